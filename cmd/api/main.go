@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -11,17 +12,25 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/fernandesenzo/shortener/internal/analytics"
+	"github.com/fernandesenzo/shortener/internal/apikey"
 	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/clientip"
+	"github.com/fernandesenzo/shortener/internal/config"
+	"github.com/fernandesenzo/shortener/internal/health"
 	"github.com/fernandesenzo/shortener/internal/jwt"
+	"github.com/fernandesenzo/shortener/internal/moderation"
 	platform "github.com/fernandesenzo/shortener/internal/platform/cache"
 	"github.com/fernandesenzo/shortener/internal/platform/postgres"
+	"github.com/fernandesenzo/shortener/internal/qrcode"
+	"github.com/fernandesenzo/shortener/internal/ratelimit"
 	"github.com/fernandesenzo/shortener/internal/shortener"
+	"github.com/fernandesenzo/shortener/internal/tracing"
 	"github.com/fernandesenzo/shortener/internal/user"
-	"github.com/joho/godotenv"
 )
 
 func main() {
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil)))
 	slog.SetDefault(logger)
 
 	if err := run(); err != nil {
@@ -31,22 +40,31 @@ func main() {
 }
 
 func run() error {
-	if err := godotenv.Load(); err != nil {
-		slog.Warn("cannot load .env file. assuming env variables are set")
-	}
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to an optional YAML config file")
+	flag.Parse()
 
-	dbURL := os.Getenv("DATABASE_URL")
-	redisURL := os.Getenv("REDIS_URL")
-	if dbURL == "" || redisURL == "" {
-		return errors.New("DATABASE_URL and REDIS_URL must be set")
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		ServiceName: "shortener",
+		Exporter:    cfg.Tracing.Exporter,
+		FilePath:    cfg.Tracing.File,
+	})
+	if err != nil {
+		return err
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush pending spans", "error", err)
+		}
+	}()
 
-	db, err := postgres.NewConnection(dbURL)
+	db, err := postgres.NewConnection(cfg.Database.URL)
 	if err != nil {
 		slog.Error("postgres connection failed", "error", err)
 		return err
@@ -64,7 +82,7 @@ func run() error {
 		return err
 	}
 
-	redisClient, err := platform.NewRedisClient(redisURL)
+	redisClient, err := platform.NewRedisClient(cfg.Redis.URL)
 	if err != nil {
 		slog.Error("redis connection failed", "error", err)
 		return err
@@ -79,40 +97,130 @@ func run() error {
 
 	slog.Info("infrastructure connected")
 
-	pgRepo := shortener.NewPostgresRepository(db)
+	pgRepo := shortener.NewPostgresRepository(db, cfg.Links.DefaultQuota)
 	redisRepo := shortener.NewRedisRepository(redisClient)
-	repo := shortener.NewHybridLinkRepository(pgRepo, redisRepo)
-	service := shortener.NewService(repo)
-	handler := shortener.NewHandler(service)
+	repo := shortener.NewHybridLinkRepository(pgRepo, redisRepo, cfg.Links.CacheTTL)
 
-	pgRepoUser := user.NewPostgresRepository(db)
-	serviceUser := user.NewService(pgRepoUser)
-	handlerUser := user.NewHandler(serviceUser)
+	var blocklist *shortener.Blocklist
+	if cfg.Links.BlocklistFile != "" {
+		blocklist, err = shortener.LoadBlocklist(cfg.Links.BlocklistFile)
+		if err != nil {
+			return fmt.Errorf("loading link blocklist: %w", err)
+		}
+		slog.Info("link blocklist loaded", "path", cfg.Links.BlocklistFile, "domains", blocklist.Len())
+
+		watchCtx, stopWatching := context.WithCancel(context.Background())
+		defer stopWatching()
+		go blocklist.Watch(watchCtx, cfg.Links.BlocklistReload)
+	}
+	urlValidator := shortener.NewURLValidator(shortener.URLValidatorConfig{
+		BaseURL:          cfg.Server.BaseURL,
+		ShortenerDomains: cfg.Links.ShortenerDomains,
+		Blocklist:        blocklist,
+	})
+	service := shortener.NewService(repo, cfg.Links.TemporaryTTL, urlValidator)
+
+	if cfg.Analytics.IPHashSalt == "" {
+		slog.Warn("IP_HASH_SALT is not set. visitor ip hashes will be unsalted")
+	}
+	pgRepoAnalytics := analytics.NewPostgresRepository(db)
+	clickRecorder := analytics.NewRecorder(pgRepoAnalytics, cfg.Analytics.IPHashSalt)
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := clickRecorder.Close(ctx); err != nil {
+			slog.Error("failed to flush pending clicks", "error", err)
+		}
+	}()
+	serviceAnalytics := analytics.NewService(pgRepoAnalytics)
+	handlerAnalytics := analytics.NewHandler(serviceAnalytics)
 
-	jwtManager := jwt.NewManager(os.Getenv("JWT_SECRET_KEY"), time.Hour)
+	handler := shortener.NewHandler(service, clickRecorder)
+	handlerQR := qrcode.NewHandler(service, cfg.Server.BaseURL)
+
+	jwtKeys, err := jwt.LoadKeyring(jwt.KeyringConfig{
+		Secret:               cfg.Auth.JWTSecret,
+		PreviousSecrets:      cfg.Auth.JWTPreviousSecrets,
+		SigningKeyFile:       cfg.Auth.JWTSigningKeyFile,
+		VerificationKeyFiles: cfg.Auth.JWTVerificationKeyFiles,
+	})
+	if err != nil {
+		return fmt.Errorf("loading jwt keys: %w", err)
+	}
+	jwtManager := jwt.NewKeyringManager(jwtKeys, jwt.ManagerConfig{
+		TTL:      cfg.Auth.AccessTokenTTL,
+		Issuer:   cfg.Auth.JWTIssuer,
+		Audience: cfg.Auth.JWTAudience,
+		Leeway:   cfg.Auth.JWTLeeway,
+	})
 	pgRepoAuth := auth.NewPostgresRepository(db)
-	serviceAuth := auth.NewService(pgRepoAuth, jwtManager)
+	denylist := auth.NewRedisDenylist(redisClient)
+	loginGuard := auth.NewRedisLoginGuard(redisClient, auth.GuardConfig{
+		MaxAttempts:      cfg.Auth.LoginMaxAttempts,
+		MaxAttemptsPerIP: cfg.Auth.LoginMaxAttemptsPerIP,
+		BaseDelay:        cfg.Auth.LoginLockout,
+		MaxDelay:         cfg.Auth.LoginMaxLockout,
+		Window:           cfg.Auth.LoginFailureWindow,
+	})
+	serviceAuth := auth.NewService(pgRepoAuth, jwtManager, denylist, loginGuard, cfg.Auth.RefreshTokenTTL)
 	handlerAuth := auth.NewHandler(serviceAuth)
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("POST /api/links", handler.Shorten)
-	mux.HandleFunc("GET /{code}", handler.Get)
-	mux.HandleFunc("POST /api/users", handlerUser.Create)
-	mux.HandleFunc("POST /api/login", handlerAuth.Login)
-	mux.Handle("DELETE /api/links/{code}", RequireAuthMiddleware(http.HandlerFunc(handler.Delete)))
+	pgRepoUser := user.NewPostgresRepository(db, cfg.Links.DefaultQuota)
+	serviceUser := user.NewService(pgRepoUser, serviceAuth, redisRepo)
+	handlerUser := user.NewHandler(serviceUser)
+
+	pgRepoAPIKey := apikey.NewPostgresRepository(db)
+	serviceAPIKey := apikey.NewService(pgRepoAPIKey)
+	handlerAPIKey := apikey.NewHandler(serviceAPIKey)
+
+	pgRepoModeration := moderation.NewPostgresRepository(db)
+	serviceModeration := moderation.NewService(pgRepoModeration, service)
+	handlerModeration := moderation.NewHandler(serviceModeration)
 
-	handlerStack := AuthMiddleware(mux, jwtManager)
-	handlerStack = RateLimitMiddleware(handlerStack, redisClient, 10, time.Hour)
+	ipResolver, err := clientip.NewResolver(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return err
+	}
+
+	mux := apiRoutes(handlers{
+		links:      handler,
+		qr:         handlerQR,
+		users:      handlerUser,
+		auth:       handlerAuth,
+		analytics:  handlerAnalytics,
+		apiKeys:    handlerAPIKey,
+		moderation: handlerModeration,
+		tokens:     jwtManager,
+		limiter:    ratelimit.NewLimiter(redisClient),
+		limits: rateLimits{
+			write:  ratelimit.Policy{Name: "write", Requests: cfg.RateLimit.Requests, Window: cfg.RateLimit.Window},
+			login:  ratelimit.Policy{Name: "login", Requests: cfg.RateLimit.LoginRequests, Window: cfg.RateLimit.LoginWindow},
+			signup: ratelimit.Policy{Name: "signup", Requests: cfg.RateLimit.SignupRequests, Window: cfg.RateLimit.SignupWindow},
+		},
+	})
+
+	handlerStack := MetricsMiddleware(RouteSpanMiddleware(mux))
+	handlerStack = AuthMiddleware(handlerStack, jwtManager, denylist, serviceAPIKey)
+	handlerStack = ClientIPMiddleware(handlerStack, ipResolver)
 	handlerStack = CORSMiddleware(handlerStack)
 	handlerStack = RecoverMiddleware(handlerStack)
 	handlerStack = LoggingMiddleware(handlerStack)
+	handlerStack = TracingMiddleware(handlerStack)
+
+	healthHandler := health.NewHandler(cfg.Health.ReadinessTimeout)
+	healthHandler.AddCheck("postgres", db.PingContext)
+	healthHandler.AddCheck("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+
+	root := rootRoutes(healthHandler, handlerStack)
 
 	srv := &http.Server{
-		Addr:         ":" + port,
-		Handler:      handlerStack,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  120 * time.Second,
+		Addr:         ":" + cfg.Server.Port,
+		Handler:      RecoverMiddleware(root),
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
 	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
@@ -121,7 +229,7 @@ func run() error {
 	serverErrors := make(chan error, 1)
 
 	go func() {
-		slog.Info("server starting", "port", port)
+		slog.Info("server starting", "port", cfg.Server.Port)
 		serverErrors <- srv.ListenAndServe()
 	}()
 
@@ -133,7 +241,13 @@ func run() error {
 	case <-ctx.Done():
 		slog.Info("shutting down OS signal received")
 
-		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
+		healthHandler.SetReady(false)
+		if cfg.Server.ShutdownDelay > 0 {
+			slog.Info("waiting before shutdown so the instance is removed from rotation", "delay", cfg.Server.ShutdownDelay.String())
+			time.Sleep(cfg.Server.ShutdownDelay)
+		}
+
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 		defer cancelShutdown()
 
 		if err := srv.Shutdown(shutdownCtx); err != nil {
@@ -0,0 +1,12 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+// RequireAdminMiddleware guards the admin api: callers must be signed in with the admin role.
+func RequireAdminMiddleware(next http.Handler) http.Handler {
+	return RequireAuthMiddleware(RequireRoleMiddleware(next, domain.RoleAdmin))
+}
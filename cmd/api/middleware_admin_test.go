@@ -0,0 +1,44 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestRequireAdminMiddleware(t *testing.T) {
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	admin := &identity.Principal{UserID: "1", Roles: []string{domain.RoleAdmin}}
+
+	tests := []struct {
+		name           string
+		principal      *identity.Principal
+		expectedStatus int
+	}{
+		{name: "admin role", principal: admin, expectedStatus: http.StatusOK},
+		{name: "user role", principal: &identity.Principal{UserID: "1", Roles: []string{domain.RoleUser}}, expectedStatus: http.StatusForbidden},
+		{name: "anonymous", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mw := RequireAdminMiddleware(nextHandler)
+
+			req := httptest.NewRequest("GET", "/api/admin/users", nil)
+			if tt.principal != nil {
+				req = req.WithContext(identity.WithPrincipal(req.Context(), *tt.principal))
+			}
+			rr := httptest.NewRecorder()
+			mw.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
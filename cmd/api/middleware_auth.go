@@ -1,26 +1,82 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/identity"
 	"github.com/fernandesenzo/shortener/internal/jwt"
 )
 
-func AuthMiddleware(next http.Handler, jwtManager *jwt.Manager) http.Handler {
+type TokenDenylist interface {
+	IsDenied(ctx context.Context, tokenID string) (bool, error)
+	IsUserDenied(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, rawKey string) (*domain.APIKey, error)
+}
+
+func AuthMiddleware(next http.Handler, jwtManager *jwt.Manager, denylist TokenDenylist, apiKeys APIKeyAuthenticator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		userID := ""
+		ctx := r.Context()
+		var principal identity.Principal
 		authHeader := r.Header.Get("Authorization")
-		if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
+		if rawKey := apiKeyFromRequest(r); rawKey != "" {
+			key, err := apiKeys.Authenticate(ctx, rawKey)
+			if err == nil {
+				principal = identity.Principal{
+					UserID:       key.UserID,
+					Method:       identity.MethodAPIKey,
+					CredentialID: key.ID,
+					// keys are always restricted, even when granted no scope at all
+					Scopes: append([]string{}, key.Scopes...),
+				}
+			} else if !errors.Is(err, domain.ErrInvalidAPIKey) {
+				slog.ErrorContext(ctx, "auth: failed to authenticate api key", "error", err)
+			}
+		} else if authHeader != "" && strings.HasPrefix(authHeader, "Bearer ") {
 			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-			parsedID, err := jwtManager.ValidateToken(tokenString)
+			claims, err := jwtManager.ValidateToken(tokenString)
 			if err == nil {
-				userID = parsedID
+				denied, err := denylist.IsDenied(ctx, claims.TokenID)
+				if err == nil && !denied {
+					denied, err = denylist.IsUserDenied(ctx, claims.UserID, claims.IssuedAt)
+				}
+				if err != nil {
+					slog.ErrorContext(ctx, "auth: failed to check token denylist", "error", err)
+				} else if !denied {
+					principal = identity.Principal{
+						UserID:       claims.UserID,
+						Method:       identity.MethodToken,
+						CredentialID: claims.TokenID,
+						Roles:        claims.Roles,
+						Scopes:       claims.Scopes,
+						ExpiresAt:    claims.ExpiresAt,
+					}
+				}
 			}
 		}
-		ctx := identity.WithUserID(r.Context(), userID)
+		if principal.UserID != "" {
+			ctx = identity.WithPrincipal(ctx, principal)
+		} else {
+			ctx = identity.WithUserID(ctx, "")
+		}
 		next.ServeHTTP(w, r.WithContext(ctx))
-
 	})
 }
+
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "ApiKey "); ok {
+		return key
+	}
+	return ""
+}
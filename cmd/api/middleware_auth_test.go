@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	mr := miniredis.RunT(t)
+	denylist := auth.NewRedisDenylist(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+
+	apiKeys := stubAPIKeys{"shk_valid": {ID: "key-1", UserID: "user-2", Scopes: []string{domain.ScopeLinksWrite}}}
+
+	var gotUserID string
+	var gotPrincipal identity.Principal
+	var gotWrite, gotRead bool
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUserID, _ = identity.GetUserID(r.Context())
+		gotPrincipal, _ = identity.GetPrincipal(r.Context())
+		gotWrite = identity.HasScope(r.Context(), domain.ScopeLinksWrite)
+		gotRead = identity.HasScope(r.Context(), domain.ScopeLinksRead)
+	})
+	mw := AuthMiddleware(nextHandler, jwtManager, denylist, apiKeys)
+
+	valid, _ := jwtManager.GenerateToken("user-1")
+	validClaims, _ := jwtManager.ValidateToken(valid)
+	revoked, _ := jwtManager.GenerateToken("user-1")
+	claims, _ := jwtManager.ValidateToken(revoked)
+	_ = denylist.Deny(context.Background(), claims.TokenID, time.Hour)
+	// sessions of user-3 were revoked after its token was issued
+	revokedUser, _ := jwtManager.GenerateToken("user-3")
+	mr.Set("jwt:deny:user:user-3", strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10))
+
+	tests := []struct {
+		name       string
+		header     string
+		apiKey     string
+		wantUserID string
+		wantRead   bool
+		wantCred   string
+	}{
+		{name: "valid token", header: "Bearer " + valid, wantUserID: "user-1", wantRead: true, wantCred: validClaims.TokenID},
+		{name: "no token", header: "", wantUserID: "", wantRead: true},
+		{name: "malformed token", header: "Bearer nope", wantUserID: "", wantRead: true},
+		{name: "revoked token", header: "Bearer " + revoked, wantUserID: "", wantRead: true},
+		{name: "revoked user sessions", header: "Bearer " + revokedUser, wantUserID: "", wantRead: true},
+		{name: "api key in authorization header", header: "ApiKey shk_valid", wantUserID: "user-2", wantCred: "key-1"},
+		{name: "api key in x-api-key header", apiKey: "shk_valid", wantUserID: "user-2", wantCred: "key-1"},
+		{name: "unknown api key", apiKey: "shk_unknown", wantUserID: "", wantRead: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUserID = "unset"
+			gotPrincipal = identity.Principal{}
+			req := httptest.NewRequest("GET", "/api/links", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			if tt.apiKey != "" {
+				req.Header.Set("X-API-Key", tt.apiKey)
+			}
+			mw.ServeHTTP(httptest.NewRecorder(), req)
+
+			if gotUserID != tt.wantUserID {
+				t.Errorf("expected user %q, got %q", tt.wantUserID, gotUserID)
+			}
+			if gotPrincipal.CredentialID != tt.wantCred {
+				t.Errorf("expected credential %q, got %q", tt.wantCred, gotPrincipal.CredentialID)
+			}
+			if !gotWrite {
+				t.Error("expected links:write to be allowed")
+			}
+			if gotRead != tt.wantRead {
+				t.Errorf("expected links:read allowed=%v, got %v", tt.wantRead, gotRead)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_TokenClaims(t *testing.T) {
+	mr := miniredis.RunT(t)
+	denylist := auth.NewRedisDenylist(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	jwtManager := jwt.NewManager("test-secret", time.Hour)
+
+	var got identity.Principal
+	var gotRead, gotWrite bool
+	mw := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = identity.GetPrincipal(r.Context())
+		gotRead = identity.HasScope(r.Context(), domain.ScopeLinksRead)
+		gotWrite = identity.HasScope(r.Context(), domain.ScopeLinksWrite)
+	}), jwtManager, denylist, stubAPIKeys{})
+
+	token, _ := jwtManager.GenerateTokenWithClaims("user-1", jwt.CustomClaims{
+		Roles:  []string{"admin"},
+		Scopes: []string{domain.ScopeLinksRead},
+	})
+	req := httptest.NewRequest("GET", "/api/links", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	mw.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got.UserID != "user-1" || got.Method != identity.MethodToken || !got.HasRole("admin") || got.ExpiresAt.IsZero() {
+		t.Errorf("unexpected principal %+v", got)
+	}
+	if !gotRead || gotWrite {
+		t.Errorf("expected the token scopes to restrict the request, read=%v write=%v", gotRead, gotWrite)
+	}
+}
+
+type stubAPIKeys map[string]*domain.APIKey
+
+func (s stubAPIKeys) Authenticate(_ context.Context, rawKey string) (*domain.APIKey, error) {
+	key, ok := s[rawKey]
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	return key, nil
+}
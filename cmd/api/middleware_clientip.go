@@ -0,0 +1,16 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/clientip"
+)
+
+// ClientIPMiddleware resolves the client address once so later handlers can read it
+// with clientip.FromRequest.
+func ClientIPMiddleware(next http.Handler, resolver *clientip.Resolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := clientip.WithIP(r.Context(), resolver.Resolve(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
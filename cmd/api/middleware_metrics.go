@@ -0,0 +1,26 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/metrics"
+)
+
+// MetricsMiddleware must wrap the ServeMux directly: the mux records the matched
+// pattern on the request it receives, which is only visible here if no middleware
+// in between replaced the request.
+func MetricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		wrapped := &wrappedWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		next.ServeHTTP(wrapped, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveRequest(r.Method, route, wrapped.statusCode, time.Since(start))
+	})
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/metrics"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/links/{code}/stats", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	mw := MetricsMiddleware(mux)
+
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/links/abc123/stats", nil))
+	mw.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere/at/all", nil))
+
+	rr := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+
+	want := []string{
+		`shortener_http_requests_total{method="GET",route="GET /api/links/{code}/stats",status="418"} 1`,
+		`shortener_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+	}
+	for _, line := range want {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected metrics output to contain %q", line)
+		}
+	}
+	if strings.Contains(body, "abc123") {
+		t.Error("expected raw paths to never be used as labels")
+	}
+}
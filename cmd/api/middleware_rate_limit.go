@@ -3,56 +3,46 @@ package main
 import (
 	"fmt"
 	"log/slog"
-	"net"
+	"math"
 	"net/http"
-	"strings"
-	"time"
+	"strconv"
 
-	"github.com/redis/go-redis/v9"
+	"github.com/fernandesenzo/shortener/internal/clientip"
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/metrics"
+	"github.com/fernandesenzo/shortener/internal/problem"
+	"github.com/fernandesenzo/shortener/internal/ratelimit"
 )
 
-func RateLimitMiddleware(next http.Handler, client *redis.Client, ipLimit int, window time.Duration) http.Handler {
+// RateLimitMiddleware applies policy to the authenticated user, or to the client address
+// for anonymous requests, and reports the state of the limit in RateLimit-* headers.
+// Requests are let through when redis is unavailable.
+func RateLimitMiddleware(next http.Handler, limiter *ratelimit.Limiter, policy ratelimit.Policy) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
-		default:
-			next.ServeHTTP(w, r)
-			return
-		}
-
 		ctx := r.Context()
 
-		var ip string
-		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-			ips := strings.Split(forwarded, ",")
-			ip = strings.TrimSpace(ips[0])
-		} else {
-			var err error
-			ip, _, err = net.SplitHostPort(r.RemoteAddr)
-			if err != nil {
-				ip = r.RemoteAddr
-			}
+		key := "ip:" + clientip.FromRequest(r)
+		if userID, ok := identity.GetUserID(ctx); ok && userID != "" {
+			key = "user:" + userID
 		}
 
-		now := time.Now().UTC()
-		windowStart := now.Truncate(window)
-		key := fmt.Sprintf("rl:w:ip:%s:%d", ip, windowStart.Unix())
-
-		count, err := client.Incr(ctx, key).Result()
+		res, err := limiter.Allow(ctx, policy, key)
 		if err != nil {
-			slog.ErrorContext(ctx, "ratelimiter: redis failed", "error", err, "ip", ip)
+			slog.ErrorContext(ctx, "ratelimiter: redis failed", "error", err, "policy", policy.Name, "key", key)
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		if count == 1 {
-			client.Expire(ctx, key, window)
-		}
+		reset := strconv.Itoa(int(math.Ceil(res.Reset.Seconds())))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Requests, int(policy.Window.Seconds())))
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		w.Header().Set("RateLimit-Reset", reset)
 
-		if count > int64(ipLimit) {
-			remaining := int(windowStart.Add(window).Sub(now).Seconds())
-			w.Header().Set("Retry-After", fmt.Sprintf("%d", remaining))
-			http.Error(w, "too many requests", http.StatusTooManyRequests)
+		if !res.Allowed {
+			metrics.IncRateLimitRejection()
+			w.Header().Set("Retry-After", reset)
+			problem.Write(w, r, http.StatusTooManyRequests, problem.CodeRateLimited, "too many requests, retry later")
 			return
 		}
 
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/ratelimit"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -20,89 +22,96 @@ func TestRateLimitMiddleware(t *testing.T) {
 	client := redis.NewClient(&redis.Options{
 		Addr: mr.Addr(),
 	})
+	limiter := ratelimit.NewLimiter(client)
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
-	limit := 1
-	window := time.Minute
+	policy := ratelimit.Policy{Name: "test", Requests: 1, Window: time.Minute}
 
-	t.Run("X-Forwarded-For Priority", func(t *testing.T) {
-		mr.FlushAll()
-		mw := RateLimitMiddleware(nextHandler, client, limit, window)
+	serve := func(mw http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mw.ServeHTTP(rr, req)
+		return rr
+	}
 
-		req1 := httptest.NewRequest("POST", "/api/links", nil)
-		req1.Header.Set("X-Forwarded-For", "1.2.3.4")
-		rr1 := httptest.NewRecorder()
-		mw.ServeHTTP(rr1, req1)
+	t.Run("RemoteAddr Key", func(t *testing.T) {
+		mr.FlushAll()
+		mw := RateLimitMiddleware(nextHandler, limiter, policy)
 
-		if rr1.Code != http.StatusOK {
-			t.Errorf("expected 200, got %d", rr1.Code)
+		req := httptest.NewRequest("POST", "/api/links", nil)
+		req.RemoteAddr = "127.0.0.1:1234"
+		if rr := serve(mw, req); rr.Code != http.StatusOK {
+			t.Errorf("expected 200, got %d", rr.Code)
 		}
-
-		req2 := httptest.NewRequest("POST", "/api/links", nil)
-		req2.Header.Set("X-Forwarded-For", "1.2.3.4")
-		rr2 := httptest.NewRecorder()
-		mw.ServeHTTP(rr2, req2)
-
-		if rr2.Code != http.StatusTooManyRequests {
-			t.Errorf("expected 429, got %d", rr2.Code)
+		if rr := serve(mw, req); rr.Code != http.StatusTooManyRequests {
+			t.Errorf("expected 429, got %d", rr.Code)
 		}
 	})
 
-	t.Run("RemoteAddr Fallback", func(t *testing.T) {
+	t.Run("Ignores Unresolved X-Forwarded-For", func(t *testing.T) {
 		mr.FlushAll()
-		mw := RateLimitMiddleware(nextHandler, client, limit, window)
-
-		req1 := httptest.NewRequest("POST", "/api/links", nil)
-		req1.RemoteAddr = "127.0.0.1:1234"
-		rr1 := httptest.NewRecorder()
-		mw.ServeHTTP(rr1, req1)
-
-		if rr1.Code != http.StatusOK {
-			t.Errorf("expected 200, got %d", rr1.Code)
+		mw := RateLimitMiddleware(nextHandler, limiter, policy)
+
+		for i, spoofed := range []string{"1.1.1.1", "2.2.2.2"} {
+			req := httptest.NewRequest("POST", "/api/links", nil)
+			req.RemoteAddr = "8.8.8.8:1234"
+			req.Header.Set("X-Forwarded-For", spoofed)
+			rr := serve(mw, req)
+			if i == 1 && rr.Code != http.StatusTooManyRequests {
+				t.Errorf("expected spoofed header to be ignored, got %d", rr.Code)
+			}
 		}
+	})
 
-		req2 := httptest.NewRequest("POST", "/api/links", nil)
-		req2.RemoteAddr = "127.0.0.1:1234"
-		rr2 := httptest.NewRecorder()
-		mw.ServeHTTP(rr2, req2)
-
-		if rr2.Code != http.StatusTooManyRequests {
-			t.Errorf("expected 429, got %d", rr2.Code)
+	t.Run("Authenticated Users Have Their Own Limit", func(t *testing.T) {
+		mr.FlushAll()
+		mw := RateLimitMiddleware(nextHandler, limiter, policy)
+
+		for _, userID := range []string{"user-1", "user-2"} {
+			req := httptest.NewRequest("POST", "/api/links", nil)
+			req.RemoteAddr = "8.8.8.8:1234"
+			req = req.WithContext(identity.WithUserID(req.Context(), userID))
+			if rr := serve(mw, req); rr.Code != http.StatusOK {
+				t.Errorf("expected %s behind a shared address to be allowed, got %d", userID, rr.Code)
+			}
 		}
 	})
 
-	t.Run("Skip Rate Limit on GET", func(t *testing.T) {
+	t.Run("RateLimit Headers", func(t *testing.T) {
 		mr.FlushAll()
-		mw := RateLimitMiddleware(nextHandler, client, 0, window)
+		mw := RateLimitMiddleware(nextHandler, limiter, ratelimit.Policy{Name: "test", Requests: 2, Window: time.Minute})
 
-		req := httptest.NewRequest("GET", "/abc", nil)
-		rr := httptest.NewRecorder()
-		mw.ServeHTTP(rr, req)
+		req := httptest.NewRequest("POST", "/api/links", nil)
+		req.RemoteAddr = "8.8.8.8:1234"
+		rr := serve(mw, req)
+		if got := rr.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("expected RateLimit-Limit 2, got %q", got)
+		}
+		if got := rr.Header().Get("RateLimit-Remaining"); got != "1" {
+			t.Errorf("expected RateLimit-Remaining 1, got %q", got)
+		}
+		if got := rr.Header().Get("RateLimit-Policy"); got != "2;w=60" {
+			t.Errorf("expected RateLimit-Policy 2;w=60, got %q", got)
+		}
 
-		if rr.Code != http.StatusOK {
-			t.Errorf("expected 200, got %d", rr.Code)
+		serve(mw, req)
+		rr = serve(mw, req)
+		if rr.Code != http.StatusTooManyRequests {
+			t.Fatalf("expected 429, got %d", rr.Code)
+		}
+		if got := rr.Header().Get("Retry-After"); got != "60" {
+			t.Errorf("expected Retry-After 60, got %q", got)
 		}
 	})
 
-	t.Run("Retry-After Header", func(t *testing.T) {
-		mr.FlushAll()
-		mw := RateLimitMiddleware(nextHandler, client, 1, window)
-
-		req1 := httptest.NewRequest("POST", "/api/links", nil)
-		req1.RemoteAddr = "8.8.8.8:1234"
-		mw.ServeHTTP(httptest.NewRecorder(), req1)
-
-		req2 := httptest.NewRequest("POST", "/api/links", nil)
-		req2.RemoteAddr = "8.8.8.8:1234"
-		rr2 := httptest.NewRecorder()
-		mw.ServeHTTP(rr2, req2)
+	t.Run("Fails Open", func(t *testing.T) {
+		broken := ratelimit.NewLimiter(redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"}))
+		mw := RateLimitMiddleware(nextHandler, broken, policy)
 
-		retryAfter := rr2.Header().Get("Retry-After")
-		if retryAfter == "" {
-			t.Error("expected Retry-After header, got empty string")
+		if rr := serve(mw, httptest.NewRequest("POST", "/api/links", nil)); rr.Code != http.StatusOK {
+			t.Errorf("expected 200 when redis is down, got %d", rr.Code)
 		}
 	})
 }
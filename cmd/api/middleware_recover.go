@@ -3,6 +3,8 @@ package main
 import (
 	"log/slog"
 	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/problem"
 )
 
 func RecoverMiddleware(next http.Handler) http.Handler {
@@ -14,7 +16,7 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 					"method", r.Method,
 					"path", r.URL.Path,
 				)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "internal server error")
 			}
 		}()
 		next.ServeHTTP(w, r)
@@ -4,13 +4,14 @@ import (
 	"net/http"
 
 	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/problem"
 )
 
 func RequireAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		_, ok := identity.GetUserID(r.Context())
-		if !ok {
-			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		userID, ok := identity.GetUserID(r.Context())
+		if !ok || userID == "" {
+			problem.Write(w, r, http.StatusUnauthorized, problem.CodeUnauthenticated, "authentication required")
 			return
 		}
 		next.ServeHTTP(w, r)
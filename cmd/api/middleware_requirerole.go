@@ -0,0 +1,21 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+// RequireRoleMiddleware lets through principals holding role. API keys carry no
+// roles, so they never pass.
+func RequireRoleMiddleware(next http.Handler, role string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := identity.GetPrincipal(r.Context())
+		if !ok || !principal.HasRole(role) {
+			problem.Write(w, r, http.StatusForbidden, problem.CodeInsufficientRole, "requires the "+role+" role")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestRequireRoleMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := RequireRoleMiddleware(next, domain.RoleAdmin)
+
+	tests := []struct {
+		name       string
+		principal  *identity.Principal
+		wantStatus int
+	}{
+		{name: "admin", principal: &identity.Principal{UserID: "1", Roles: []string{domain.RoleAdmin}}, wantStatus: http.StatusOK},
+		{name: "regular user", principal: &identity.Principal{UserID: "1", Roles: []string{domain.RoleUser}}, wantStatus: http.StatusForbidden},
+		{name: "api key", principal: &identity.Principal{UserID: "1", Method: identity.MethodAPIKey, Scopes: []string{}}, wantStatus: http.StatusForbidden},
+		{name: "anonymous", wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/users", nil)
+			if tt.principal != nil {
+				req = req.WithContext(identity.WithPrincipal(req.Context(), *tt.principal))
+			}
+			rr := httptest.NewRecorder()
+			mw.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+func RequireScopeMiddleware(next http.Handler, scope string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !identity.HasScope(r.Context(), scope) {
+			problem.Write(w, r, http.StatusForbidden, problem.CodeInsufficientScope, "api key lacks the "+scope+" scope")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestRequireScopeMiddleware(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mw := RequireScopeMiddleware(next, domain.ScopeLinksWrite)
+
+	tests := []struct {
+		name       string
+		scopes     []string
+		wantStatus int
+	}{
+		{name: "unscoped session", scopes: nil, wantStatus: http.StatusOK},
+		{name: "key with scope", scopes: []string{domain.ScopeLinksWrite}, wantStatus: http.StatusOK},
+		{name: "key without scope", scopes: []string{domain.ScopeLinksRead}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/links", nil)
+			if tt.scopes != nil {
+				req = req.WithContext(identity.WithScopes(req.Context(), tt.scopes))
+			}
+			rr := httptest.NewRecorder()
+			mw.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/analytics"
+	"github.com/fernandesenzo/shortener/internal/apikey"
+	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/health"
+	"github.com/fernandesenzo/shortener/internal/jwt"
+	"github.com/fernandesenzo/shortener/internal/metrics"
+	"github.com/fernandesenzo/shortener/internal/moderation"
+	"github.com/fernandesenzo/shortener/internal/openapi"
+	"github.com/fernandesenzo/shortener/internal/qrcode"
+	"github.com/fernandesenzo/shortener/internal/ratelimit"
+	"github.com/fernandesenzo/shortener/internal/shortener"
+	"github.com/fernandesenzo/shortener/internal/user"
+)
+
+// handlers groups the HTTP handlers mounted on the API mux.
+type handlers struct {
+	links      *shortener.Handler
+	qr         *qrcode.Handler
+	users      *user.Handler
+	auth       *auth.Handler
+	analytics  *analytics.Handler
+	apiKeys    *apikey.Handler
+	moderation *moderation.Handler
+	tokens     *jwt.Manager
+	limiter    *ratelimit.Limiter
+	limits     rateLimits
+}
+
+// rateLimits holds the policies applied to mutating routes.
+type rateLimits struct {
+	write  ratelimit.Policy
+	login  ratelimit.Policy
+	signup ratelimit.Policy
+}
+
+// routeMux is a ServeMux that remembers the patterns registered on it, so the
+// tests can check every route against the OpenAPI document.
+type routeMux struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func newRouteMux() *routeMux {
+	return &routeMux{ServeMux: http.NewServeMux()}
+}
+
+func (m *routeMux) Handle(pattern string, handler http.Handler) {
+	m.patterns = append(m.patterns, pattern)
+	m.ServeMux.Handle(pattern, handler)
+}
+
+func (m *routeMux) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	m.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// apiRoutes returns the mux serving the API. It sits behind the full middleware stack.
+func apiRoutes(h handlers) *routeMux {
+	limit := func(policy ratelimit.Policy, next http.Handler) http.Handler {
+		return RateLimitMiddleware(next, h.limiter, policy)
+	}
+	admin := func(next http.HandlerFunc) http.Handler {
+		return RequireAdminMiddleware(next)
+	}
+
+	mux := newRouteMux()
+	mux.Handle("POST /api/links", limit(h.limits.write, RequireScopeMiddleware(http.HandlerFunc(h.links.Shorten), domain.ScopeLinksWrite)))
+	mux.HandleFunc("GET /{code}", h.links.Get)
+	mux.Handle("POST /{code}/report", limit(h.limits.write, http.HandlerFunc(h.moderation.Report)))
+	mux.Handle("POST /api/users", limit(h.limits.signup, http.HandlerFunc(h.users.Create)))
+	mux.Handle("GET /api/users/me", RequireAuthMiddleware(http.HandlerFunc(h.users.Me)))
+	mux.Handle("PATCH /api/users/me", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.users.UpdateMe), domain.ScopeAccountManage))))
+	mux.Handle("GET /api/users/me/quota", RequireAuthMiddleware(http.HandlerFunc(h.users.Quota)))
+	mux.Handle("PUT /api/users/me/password", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.users.ChangePassword), domain.ScopeAccountManage))))
+	mux.Handle("DELETE /api/users/me", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.users.Delete), domain.ScopeAccountManage))))
+	mux.Handle("GET /api/admin/users", admin(h.users.List))
+	mux.Handle("PUT /api/admin/users/{id}/quota", limit(h.limits.write, admin(h.users.SetQuota)))
+	mux.Handle("POST /api/admin/users/{id}/disable", limit(h.limits.write, admin(h.users.Disable)))
+	mux.Handle("POST /api/admin/users/{id}/enable", limit(h.limits.write, admin(h.users.Enable)))
+	mux.Handle("DELETE /api/admin/links/{code}", limit(h.limits.write, admin(h.links.AdminDelete)))
+	mux.Handle("POST /api/admin/links/{code}/disable", limit(h.limits.write, admin(h.moderation.DisableLink)))
+	mux.Handle("POST /api/admin/links/{code}/enable", limit(h.limits.write, admin(h.moderation.EnableLink)))
+	mux.Handle("GET /api/admin/reports", admin(h.moderation.List))
+	mux.Handle("POST /api/admin/reports/{id}/dismiss", limit(h.limits.write, admin(h.moderation.Dismiss)))
+	mux.Handle("POST /api/login", limit(h.limits.login, http.HandlerFunc(h.auth.Login)))
+	mux.Handle("POST /api/token/refresh", limit(h.limits.login, http.HandlerFunc(h.auth.Refresh)))
+	mux.Handle("POST /api/logout", limit(h.limits.write, RequireAuthMiddleware(http.HandlerFunc(h.auth.Logout))))
+	mux.Handle("GET /api/links", RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.links.List), domain.ScopeLinksRead)))
+	mux.Handle("PATCH /api/links/{code}", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.links.Update), domain.ScopeLinksWrite))))
+	mux.Handle("DELETE /api/links/{code}", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.links.Delete), domain.ScopeLinksWrite))))
+	mux.Handle("GET /api/links/{code}/stats", RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.analytics.Stats), domain.ScopeLinksRead)))
+	mux.HandleFunc("GET /api/links/{code}/qr", h.qr.Get)
+	mux.Handle("POST /api/keys", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.apiKeys.Create), domain.ScopeKeysManage))))
+	mux.Handle("GET /api/keys", RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.apiKeys.List), domain.ScopeKeysManage)))
+	mux.Handle("DELETE /api/keys/{id}", limit(h.limits.write, RequireAuthMiddleware(RequireScopeMiddleware(http.HandlerFunc(h.apiKeys.Revoke), domain.ScopeKeysManage))))
+	mux.HandleFunc("GET /.well-known/jwks.json", h.tokens.ServeJWKS)
+	mux.HandleFunc("GET /api/openapi.json", openapi.ServeSpec)
+	mux.HandleFunc("GET /api/docs", openapi.ServeDocs)
+	return mux
+}
+
+// rootRoutes returns the outermost mux. Probes and scrapes bypass the middleware
+// stack so they are neither rate limited nor logged; everything else goes to api.
+func rootRoutes(healthHandler *health.Handler, api http.Handler) *routeMux {
+	root := newRouteMux()
+	root.HandleFunc("GET /healthz", healthHandler.Live)
+	root.HandleFunc("GET /readyz", healthHandler.Ready)
+	root.Handle("GET /metrics", metrics.Handler())
+	root.Handle("/", api)
+	return root
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/openapi"
+)
+
+func documentedOperations(t *testing.T) map[string]bool {
+	t.Helper()
+	var doc struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec(), &doc); err != nil {
+		t.Fatalf("failed to parse openapi document: %v", err)
+	}
+	ops := make(map[string]bool)
+	for path, item := range doc.Paths {
+		for method := range item {
+			if method == "parameters" || method == "summary" || method == "description" {
+				continue
+			}
+			ops[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	return ops
+}
+
+func registeredRoutes() []string {
+	var patterns []string
+	patterns = append(patterns, apiRoutes(handlers{}).patterns...)
+	for _, p := range rootRoutes(nil, http.NotFoundHandler()).patterns {
+		// the catch-all only forwards to the api mux
+		if p != "/" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+func TestRoutes_AreDocumented(t *testing.T) {
+	ops := documentedOperations(t)
+	for _, pattern := range registeredRoutes() {
+		if !ops[pattern] {
+			t.Errorf("route %q is registered but missing from internal/openapi/openapi.json", pattern)
+		}
+	}
+}
+
+func TestRoutes_DocumentedOperationsExist(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, pattern := range registeredRoutes() {
+		registered[pattern] = true
+	}
+	var stale []string
+	for op := range documentedOperations(t) {
+		if !registered[op] {
+			stale = append(stale, op)
+		}
+	}
+	sort.Strings(stale)
+	for _, op := range stale {
+		t.Errorf("operation %q is documented but no route is registered for it", op)
+	}
+}
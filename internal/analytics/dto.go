@@ -0,0 +1,13 @@
+package analytics
+
+type dailyClicksResponse struct {
+	Date   string `json:"date"`
+	Clicks int64  `json:"clicks"`
+}
+
+type statsResponse struct {
+	Code           string                `json:"code"`
+	TotalClicks    int64                 `json:"totalClicks"`
+	UniqueVisitors int64                 `json:"uniqueVisitors"`
+	Daily          []dailyClicksResponse `json:"daily"`
+}
@@ -0,0 +1,56 @@
+package analytics
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+type Handler struct {
+	srv *Service
+}
+
+func NewHandler(srv *Service) *Handler {
+	return &Handler{srv: srv}
+}
+
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	days := 0
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			problem.Error(w, r, domain.ErrInvalidStatsPeriod)
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.srv.Stats(r.Context(), code, days)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	resp := statsResponse{
+		Code:           stats.Code,
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+		Daily:          make([]dailyClicksResponse, 0, len(stats.Daily)),
+	}
+	for _, d := range stats.Daily {
+		resp.Daily = append(resp.Daily, dailyClicksResponse{Date: d.Date.Format(time.DateOnly), Clicks: d.Clicks})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fernandesenzo/shortener/internal/clientip"
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const (
+	recorderBufferSize = 4096
+	recorderBatchSize  = 100
+	recorderFlushEvery = time.Second
+	maxHeaderLength    = 512
+)
+
+// Recorder collects clicks off the redirect path and persists them in batches from a single worker.
+type Recorder struct {
+	repo   Repository
+	salt   string
+	events chan domain.Click
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewRecorder(repo Repository, salt string) *Recorder {
+	r := &Recorder{
+		repo:   repo,
+		salt:   salt,
+		events: make(chan domain.Click, recorderBufferSize),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record never blocks: when the buffer is full the click is dropped.
+func (r *Recorder) Record(code string, req *http.Request) {
+	click := domain.Click{
+		Code:      code,
+		ClickedAt: time.Now().UTC(),
+		Referrer:  truncate(req.Referer()),
+		UserAgent: truncate(req.UserAgent()),
+		IPHash:    r.hashIP(clientip.FromRequest(req)),
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.events <- click:
+	default:
+		slog.WarnContext(req.Context(), "click buffer full, dropping click", "code", code)
+	}
+}
+
+// Close stops accepting clicks and waits for the buffered ones to be flushed.
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.events)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(recorderFlushEvery)
+	defer ticker.Stop()
+
+	batch := make([]domain.Click, 0, recorderBatchSize)
+	for {
+		select {
+		case click, ok := <-r.events:
+			if !ok {
+				r.flush(batch)
+				return
+			}
+			batch = append(batch, click)
+			if len(batch) >= recorderBatchSize {
+				r.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			r.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (r *Recorder) flush(batch []domain.Click) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := r.repo.SaveClicks(ctx, batch); err != nil {
+		slog.Error("failed to persist clicks", "error", err, "count", len(batch))
+	}
+}
+
+func (r *Recorder) hashIP(ip string) string {
+	sum := sha256.Sum256([]byte(r.salt + ip))
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate replaces invalid UTF-8, which postgres rejects, and cuts s to
+// maxHeaderLength bytes without splitting a rune.
+func truncate(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	if len(s) <= maxHeaderLength {
+		return s
+	}
+	cut := maxHeaderLength
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut]
+}
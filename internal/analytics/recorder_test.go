@@ -0,0 +1,81 @@
+package analytics_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fernandesenzo/shortener/internal/analytics"
+)
+
+func TestRecorder(t *testing.T) {
+	repo := &MockRepository{}
+	recorder := analytics.NewRecorder(repo, "salt")
+
+	for _, ip := range []string{"1.1.1.1:1000", "1.1.1.1:2000", "2.2.2.2:1000"} {
+		req := httptest.NewRequest("GET", "/abc123", nil)
+		req.RemoteAddr = ip
+		req.Header.Set("Referer", "https://news.example.com")
+		req.Header.Set("User-Agent", "test-agent")
+		recorder.Record("abc123", req)
+	}
+
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error closing recorder: %v", err)
+	}
+
+	clicks := repo.savedClicks()
+	if len(clicks) != 3 {
+		t.Fatalf("expected 3 clicks to be flushed on close, got %d", len(clicks))
+	}
+
+	c := clicks[0]
+	if c.Code != "abc123" || c.Referrer != "https://news.example.com" || c.UserAgent != "test-agent" {
+		t.Errorf("unexpected click: %+v", c)
+	}
+	if c.IPHash == "" || c.IPHash == "1.1.1.1" {
+		t.Errorf("expected ip to be hashed, got %q", c.IPHash)
+	}
+	if clicks[0].IPHash != clicks[1].IPHash {
+		t.Error("expected same ip to produce the same hash")
+	}
+	if clicks[0].IPHash == clicks[2].IPHash {
+		t.Error("expected different ips to produce different hashes")
+	}
+
+	recorder.Record("abc123", httptest.NewRequest("GET", "/abc123", nil))
+	if len(repo.savedClicks()) != 3 {
+		t.Error("expected clicks recorded after close to be ignored")
+	}
+}
+
+func TestRecorder_SanitizesHeaders(t *testing.T) {
+	repo := &MockRepository{}
+	recorder := analytics.NewRecorder(repo, "salt")
+
+	// "é" takes two bytes and starts at byte 511, straddling the 512 byte limit
+	longReferer := "https://example.com/" + strings.Repeat("a", 491) + "é" + "tail"
+
+	req := httptest.NewRequest("GET", "/abc123", nil)
+	req.Header.Set("Referer", longReferer)
+	req.Header.Set("User-Agent", "agent\xff")
+	recorder.Record("abc123", req)
+
+	if err := recorder.Close(context.Background()); err != nil {
+		t.Fatalf("unexpected error closing recorder: %v", err)
+	}
+
+	clicks := repo.savedClicks()
+	if len(clicks) != 1 {
+		t.Fatalf("expected 1 click, got %d", len(clicks))
+	}
+	c := clicks[0]
+	if !utf8.ValidString(c.Referrer) || c.Referrer != longReferer[:511] {
+		t.Errorf("expected referrer cut before the split rune, got %d bytes", len(c.Referrer))
+	}
+	if c.UserAgent != "agent�" {
+		t.Errorf("expected invalid UTF-8 to be replaced, got %q", c.UserAgent)
+	}
+}
@@ -0,0 +1,16 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+type Repository interface {
+	SaveClicks(ctx context.Context, clicks []domain.Click) error
+	GetStats(ctx context.Context, code string, userID string, since time.Time) (*domain.LinkStats, error)
+}
+
+var ErrRecordNotFound = errors.New("record not found")
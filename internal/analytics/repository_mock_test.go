@@ -0,0 +1,50 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/analytics"
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+var ErrMockedRepo = errors.New("forced mockdb error")
+
+type MockRepository struct {
+	mu          sync.Mutex
+	saved       []domain.Click
+	batches     int
+	stats       map[string]*domain.LinkStats
+	owners      map[string]string
+	shouldError bool
+}
+
+func (m *MockRepository) SaveClicks(_ context.Context, clicks []domain.Click) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	m.saved = append(m.saved, clicks...)
+	m.batches++
+	return nil
+}
+
+func (m *MockRepository) GetStats(_ context.Context, code string, userID string, _ time.Time) (*domain.LinkStats, error) {
+	if m.shouldError {
+		return nil, ErrMockedRepo
+	}
+	if m.owners[code] != userID {
+		return nil, analytics.ErrRecordNotFound
+	}
+	stats := *m.stats[code]
+	return &stats, nil
+}
+
+func (m *MockRepository) savedClicks() []domain.Click {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]domain.Click(nil), m.saved...)
+}
@@ -0,0 +1,110 @@
+package analytics
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+// SaveClicks stores the raw events and bumps the daily counters in one transaction.
+// Clicks on codes that only exist in redis (temporary links) are silently dropped,
+// and a click postgres rejects is rolled back to its savepoint and skipped so it
+// does not take the rest of the batch with it.
+func (r *PostgresRepository) SaveClicks(ctx context.Context, clicks []domain.Click) error {
+	query := `
+        WITH link AS (
+            SELECT id FROM links WHERE code = $1
+        ), inserted AS (
+            INSERT INTO clicks (link_id, clicked_at, referrer, user_agent, ip_hash)
+            SELECT id, $2, $3, $4, $5 FROM link
+            RETURNING link_id, clicked_at
+        )
+        INSERT INTO link_daily_clicks (link_id, day, clicks)
+        SELECT link_id, (clicked_at AT TIME ZONE 'UTC')::date, 1 FROM inserted
+        ON CONFLICT (link_id, day) DO UPDATE SET clicks = link_daily_clicks.clicks + 1`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting click transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("error preparing click insert: %w", err)
+	}
+	defer func() { _ = stmt.Close() }()
+
+	for _, c := range clicks {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT click`); err != nil {
+			return fmt.Errorf("error creating click savepoint: %w", err)
+		}
+		if _, err := stmt.ExecContext(ctx, c.Code, c.ClickedAt, c.Referrer, c.UserAgent, c.IPHash); err != nil {
+			slog.WarnContext(ctx, "skipping click rejected by the database", "code", c.Code, "error", err)
+			if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT click`); err != nil {
+				return fmt.Errorf("error rolling back click savepoint: %w", err)
+			}
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `RELEASE SAVEPOINT click`); err != nil {
+			return fmt.Errorf("error releasing click savepoint: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *PostgresRepository) GetStats(ctx context.Context, code string, userID string, since time.Time) (*domain.LinkStats, error) {
+	var linkID string
+	err := r.db.QueryRowContext(ctx, `SELECT id FROM links WHERE code = $1 AND user_id = $2`, code, userID).Scan(&linkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("error getting link for stats: %w", err)
+	}
+
+	stats := &domain.LinkStats{Code: code}
+	query := `
+        SELECT
+            (SELECT COALESCE(SUM(clicks), 0) FROM link_daily_clicks WHERE link_id = $1),
+            (SELECT COUNT(DISTINCT ip_hash) FROM clicks WHERE link_id = $1)`
+	if err := r.db.QueryRowContext(ctx, query, linkID).Scan(&stats.TotalClicks, &stats.UniqueVisitors); err != nil {
+		return nil, fmt.Errorf("error getting click totals: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT day, clicks FROM link_daily_clicks
+        WHERE link_id = $1 AND day >= $2::date
+        ORDER BY day`, linkID, since.UTC().Format(time.DateOnly))
+	if err != nil {
+		return nil, fmt.Errorf("error getting daily clicks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d domain.DailyClicks
+		if err := rows.Scan(&d.Date, &d.Clicks); err != nil {
+			return nil, fmt.Errorf("error scanning daily clicks: %w", err)
+		}
+		stats.Daily = append(stats.Daily, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily clicks: %w", err)
+	}
+
+	return stats, nil
+}
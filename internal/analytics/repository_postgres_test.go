@@ -0,0 +1,67 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/analytics"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/testutil"
+	_ "github.com/lib/pq"
+)
+
+func TestPostgresRepository_Clicks(t *testing.T) {
+	db, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	repo := analytics.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	var userID string
+	err := db.QueryRow(`INSERT INTO users (nickname, password_hash) VALUES ('stats_user', 'hash') RETURNING id`).Scan(&userID)
+	if err != nil {
+		t.Fatalf("error inserting seed user: %v", err)
+	}
+	_, err = db.Exec(`INSERT INTO links (code, original_url, user_id) VALUES ('stats1', 'https://stats.com', $1)`, userID)
+	if err != nil {
+		t.Fatalf("error inserting seed link: %v", err)
+	}
+
+	now := time.Now().UTC()
+	yesterday := now.AddDate(0, 0, -1)
+	err = repo.SaveClicks(ctx, []domain.Click{
+		{Code: "stats1", ClickedAt: yesterday, IPHash: "a"},
+		{Code: "stats1", ClickedAt: now, IPHash: "a"},
+		{Code: "stats1", ClickedAt: now, IPHash: "b"},
+		{Code: "tempcode", ClickedAt: now, IPHash: "c"},
+		{Code: "stats1", ClickedAt: now, Referrer: "\xff", IPHash: "d"},
+	})
+	if err != nil {
+		t.Fatalf("SaveClicks() error = %v", err)
+	}
+
+	t.Run("owner gets aggregated stats", func(t *testing.T) {
+		stats, err := repo.GetStats(ctx, "stats1", userID, yesterday)
+		if err != nil {
+			t.Fatalf("GetStats() error = %v", err)
+		}
+		if stats.TotalClicks != 3 {
+			t.Errorf("expected 3 total clicks, got %d", stats.TotalClicks)
+		}
+		if stats.UniqueVisitors != 2 {
+			t.Errorf("expected 2 unique visitors, got %d", stats.UniqueVisitors)
+		}
+		if len(stats.Daily) != 2 || stats.Daily[0].Clicks != 1 || stats.Daily[1].Clicks != 2 {
+			t.Errorf("unexpected daily series: %+v", stats.Daily)
+		}
+	})
+
+	t.Run("other users cannot see stats", func(t *testing.T) {
+		_, err := repo.GetStats(ctx, "stats1", "00000000-0000-0000-0000-000000000000", yesterday)
+		if !errors.Is(err, analytics.ErrRecordNotFound) {
+			t.Errorf("expected %v, got %v", analytics.ErrRecordNotFound, err)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) Stats(ctx context.Context, code string, days int) (*domain.LinkStats, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	if days == 0 {
+		days = defaultStatsDays
+	}
+	if days < 1 || days > maxStatsDays {
+		return nil, domain.ErrInvalidStatsPeriod
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	since := today.AddDate(0, 0, -(days - 1))
+
+	stats, err := s.repo.GetStats(ctx, code, uid, since)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserCannotViewLinkStats
+		}
+		slog.ErrorContext(ctx, "error getting link stats", "userID", uid, "code", code, "error", err)
+		return nil, err
+	}
+
+	stats.Daily = fillDays(stats.Daily, since, days)
+	return stats, nil
+}
+
+// fillDays returns one entry per day in the period, including days without clicks.
+func fillDays(daily []domain.DailyClicks, since time.Time, days int) []domain.DailyClicks {
+	counts := make(map[string]int64, len(daily))
+	for _, d := range daily {
+		counts[d.Date.Format(time.DateOnly)] = d.Clicks
+	}
+
+	series := make([]domain.DailyClicks, 0, days)
+	for i := 0; i < days; i++ {
+		day := since.AddDate(0, 0, i)
+		series = append(series, domain.DailyClicks{Date: day, Clicks: counts[day.Format(time.DateOnly)]})
+	}
+	return series
+}
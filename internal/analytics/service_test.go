@@ -0,0 +1,110 @@
+package analytics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/analytics"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestService_Stats(t *testing.T) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	newRepo := func() *MockRepository {
+		return &MockRepository{
+			owners: map[string]string{"abc123": "user1"},
+			stats: map[string]*domain.LinkStats{
+				"abc123": {
+					Code:           "abc123",
+					TotalClicks:    5,
+					UniqueVisitors: 2,
+					Daily:          []domain.DailyClicks{{Date: today, Clicks: 5}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		userID        string
+		code          string
+		days          int
+		shouldError   bool
+		expectedError error
+		expectedDays  int
+	}{
+		{
+			name:          "Success with default period",
+			userID:        "user1",
+			code:          "abc123",
+			expectedError: nil,
+			expectedDays:  30,
+		},
+		{
+			name:          "Success with custom period",
+			userID:        "user1",
+			code:          "abc123",
+			days:          7,
+			expectedError: nil,
+			expectedDays:  7,
+		},
+		{
+			name:          "Unauthenticated",
+			userID:        "",
+			code:          "abc123",
+			expectedError: domain.ErrUserNotAuthenticated,
+		},
+		{
+			name:          "Not owner",
+			userID:        "user2",
+			code:          "abc123",
+			expectedError: domain.ErrUserCannotViewLinkStats,
+		},
+		{
+			name:          "Invalid period",
+			userID:        "user1",
+			code:          "abc123",
+			days:          400,
+			expectedError: domain.ErrInvalidStatsPeriod,
+		},
+		{
+			name:          "Repository error",
+			userID:        "user1",
+			code:          "abc123",
+			shouldError:   true,
+			expectedError: ErrMockedRepo,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			repo.shouldError = tt.shouldError
+			svc := analytics.NewService(repo)
+			ctx := identity.WithUserID(context.Background(), tt.userID)
+
+			stats, err := svc.Stats(ctx, tt.code, tt.days)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError != nil {
+				return
+			}
+
+			if len(stats.Daily) != tt.expectedDays {
+				t.Fatalf("expected %d days, got %d", tt.expectedDays, len(stats.Daily))
+			}
+			last := stats.Daily[len(stats.Daily)-1]
+			if !last.Date.Equal(today) || last.Clicks != 5 {
+				t.Errorf("expected today to have 5 clicks, got %+v", last)
+			}
+			if stats.Daily[0].Clicks != 0 {
+				t.Errorf("expected days without clicks to be zero, got %+v", stats.Daily[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package apikey
+
+import "time"
+
+type createRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type keyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+type createResponse struct {
+	keyResponse
+	Key string `json:"key"`
+}
+
+type listResponse struct {
+	Keys []keyResponse `json:"keys"`
+}
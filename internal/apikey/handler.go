@@ -0,0 +1,83 @@
+package apikey
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+type Handler struct {
+	srv *Service
+}
+
+func NewHandler(srv *Service) *Handler {
+	return &Handler{srv: srv}
+}
+
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req createRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	key, raw, err := h.srv.Create(r.Context(), req.Name, req.Scopes)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, r, http.StatusCreated, createResponse{keyResponse: toResponse(key), Key: raw})
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.srv.List(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	resp := listResponse{Keys: make([]keyResponse, 0, len(keys))}
+	for _, key := range keys {
+		resp.Keys = append(resp.Keys, toResponse(key))
+	}
+	h.sendJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) Revoke(w http.ResponseWriter, r *http.Request) {
+	if err := h.srv.Revoke(r.Context(), r.PathValue("id")); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode json response", "error", err)
+	}
+}
+
+func toResponse(key *domain.APIKey) keyResponse {
+	return keyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+	}
+}
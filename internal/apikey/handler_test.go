@@ -0,0 +1,108 @@
+package apikey_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/apikey"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestHandler_Create(t *testing.T) {
+	tests := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "success", reqBody: `{"name":"ci","scopes":["links:write"]}`, expectedStatus: http.StatusCreated},
+		{name: "invalid json", reqBody: `{nope`, expectedStatus: http.StatusBadRequest, expectedError: "invalid_request_body"},
+		{name: "invalid scope", reqBody: `{"name":"ci","scopes":["keys:manage"]}`, expectedStatus: http.StatusUnprocessableEntity, expectedError: "invalid_api_key_scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := apikey.NewHandler(apikey.NewService(&MockRepository{}))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/keys", bytes.NewBufferString(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(identity.WithUserID(req.Context(), "user-1"))
+			rr := httptest.NewRecorder()
+
+			handler.Create(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected code %d, got %d", tt.expectedStatus, rr.Code)
+			}
+
+			var resp struct {
+				ID   string `json:"id"`
+				Key  string `json:"key"`
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("error reading response json %v", err)
+			}
+			if tt.expectedError != "" {
+				if resp.Code != tt.expectedError {
+					t.Errorf("expected error code %q, got %q", tt.expectedError, resp.Code)
+				}
+				return
+			}
+			if resp.ID == "" || resp.Key == "" {
+				t.Errorf("expected id and key in response, got %+v", resp)
+			}
+		})
+	}
+}
+
+func TestHandler_ListAndRevoke(t *testing.T) {
+	mock := &MockRepository{}
+	srv := apikey.NewService(mock)
+	handler := apikey.NewHandler(srv)
+	ctx := identity.WithUserID(context.Background(), "user-1")
+
+	key, _, err := srv.Create(ctx, "ci", []string{domain.ScopeLinksRead})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/keys", nil).WithContext(ctx)
+	rr := httptest.NewRecorder()
+	handler.List(rr, req)
+
+	var list struct {
+		Keys []struct {
+			ID  string `json:"id"`
+			Key string `json:"key"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("error reading response json %v", err)
+	}
+	if len(list.Keys) != 1 || list.Keys[0].ID != key.ID {
+		t.Fatalf("expected one key %q, got %+v", key.ID, list.Keys)
+	}
+	if list.Keys[0].Key != "" {
+		t.Error("expected plain key to never be listed")
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/keys/"+key.ID, nil).WithContext(ctx)
+	req.SetPathValue("id", key.ID)
+	rr = httptest.NewRecorder()
+	handler.Revoke(rr, req)
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("expected code %d, got %d", http.StatusNoContent, rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.Revoke(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected code %d, got %d", http.StatusNotFound, rr.Code)
+	}
+}
@@ -0,0 +1,17 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+type Repository interface {
+	Save(ctx context.Context, key *domain.APIKey) error
+	ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error)
+	Revoke(ctx context.Context, id string, userID string) error
+	Use(ctx context.Context, keyHash string) (*domain.APIKey, error)
+}
+
+var ErrRecordNotFound = errors.New("record not found")
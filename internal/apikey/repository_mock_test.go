@@ -0,0 +1,68 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/apikey"
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+var ErrMockedError = errors.New("forced db error")
+
+type MockRepository struct {
+	keys        []*domain.APIKey
+	shouldError bool
+}
+
+func (m *MockRepository) Save(_ context.Context, key *domain.APIKey) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	key.ID = "key-" + key.Prefix
+	key.CreatedAt = time.Now()
+	m.keys = append(m.keys, key)
+	return nil
+}
+
+func (m *MockRepository) ListByUser(_ context.Context, userID string) ([]*domain.APIKey, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	var keys []*domain.APIKey
+	for _, k := range m.keys {
+		if k.UserID == userID && k.RevokedAt == nil {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockRepository) Revoke(_ context.Context, id string, userID string) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	for _, k := range m.keys {
+		if k.ID == id && k.UserID == userID && k.RevokedAt == nil {
+			now := time.Now()
+			k.RevokedAt = &now
+			return nil
+		}
+	}
+	return apikey.ErrRecordNotFound
+}
+
+func (m *MockRepository) Use(_ context.Context, keyHash string) (*domain.APIKey, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	for _, k := range m.keys {
+		if k.KeyHash == keyHash && k.RevokedAt == nil {
+			now := time.Now()
+			k.LastUsedAt = &now
+			return k, nil
+		}
+	}
+	return nil, apikey.ErrRecordNotFound
+}
@@ -0,0 +1,105 @@
+package apikey
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/lib/pq"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, key *domain.APIKey) error {
+	query := `
+        INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`
+	err := r.db.QueryRowContext(ctx, query, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Scopes)).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving api key: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ListByUser(ctx context.Context, userID string) ([]*domain.APIKey, error) {
+	query := `
+        SELECT id, user_id, name, prefix, scopes, created_at, last_used_at
+        FROM api_keys
+        WHERE user_id = $1 AND revoked_at IS NULL
+        ORDER BY created_at DESC`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		var key domain.APIKey
+		var lastUsedAt sql.NullTime
+		if err := rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedAt, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("error scanning api key: %w", err)
+		}
+		if lastUsedAt.Valid {
+			key.LastUsedAt = &lastUsedAt.Time
+		}
+		keys = append(keys, &key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating api keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (r *PostgresRepository) Revoke(ctx context.Context, id string, userID string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`, id, userID)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("error revoking api key: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// Use resolves an active key by its hash and records the time it was used. Keys of
+// disabled accounts are not found.
+func (r *PostgresRepository) Use(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	query := `
+        UPDATE api_keys SET last_used_at = now()
+        WHERE key_hash = $1 AND revoked_at IS NULL
+          AND NOT EXISTS (SELECT 1 FROM users WHERE id = api_keys.user_id AND disabled_at IS NOT NULL)
+        RETURNING id, user_id, name, prefix, scopes, created_at, last_used_at`
+	var key domain.APIKey
+	var lastUsedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, keyHash).
+		Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, pq.Array(&key.Scopes), &key.CreatedAt, &lastUsedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("error using api key: %w", err)
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return &key, nil
+}
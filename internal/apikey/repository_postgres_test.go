@@ -0,0 +1,69 @@
+package apikey_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/apikey"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/testutil"
+)
+
+func TestPostgresRepository_APIKeys(t *testing.T) {
+	db, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	repo := apikey.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	var userID string
+	err := db.QueryRowContext(ctx, `INSERT INTO users (nickname, password_hash) VALUES ('keyowner', 'hash') RETURNING id`).Scan(&userID)
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	key := &domain.APIKey{UserID: userID, Name: "ci", Prefix: "abcd1234", KeyHash: "hash-1", Scopes: []string{domain.ScopeLinksWrite}}
+	if err := repo.Save(ctx, key); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key.ID == "" {
+		t.Fatal("expected id to be set")
+	}
+
+	used, err := repo.Use(ctx, "hash-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if used.UserID != userID || used.LastUsedAt == nil || len(used.Scopes) != 1 {
+		t.Errorf("unexpected key %+v", used)
+	}
+
+	if _, err := db.ExecContext(ctx, `UPDATE users SET disabled_at = now() WHERE id = $1`, userID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Use(ctx, "hash-1"); !errors.Is(err, apikey.ErrRecordNotFound) {
+		t.Errorf("expected keys of disabled accounts to be unusable, got %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE users SET disabled_at = NULL WHERE id = $1`, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := repo.ListByUser(ctx, userID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 key, got %d", len(keys))
+	}
+
+	if err := repo.Revoke(ctx, "not-a-uuid", userID); !errors.Is(err, apikey.ErrRecordNotFound) {
+		t.Errorf("expected %v, got %v", apikey.ErrRecordNotFound, err)
+	}
+	if err := repo.Revoke(ctx, key.ID, userID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := repo.Use(ctx, "hash-1"); !errors.Is(err, apikey.ErrRecordNotFound) {
+		t.Errorf("expected revoked key to be unusable, got %v", err)
+	}
+}
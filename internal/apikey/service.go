@@ -0,0 +1,131 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+const (
+	keyPrefix     = "shk_"
+	maxNameLength = 64
+	// prefixBytes identify the key in listings, secretBytes are the part never shown again
+	prefixBytes = 4
+	secretBytes = 32
+)
+
+type Service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) *Service {
+	return &Service{repo: repo}
+}
+
+// Create mints a new key and returns it in plain text. The plain key is never stored
+// and cannot be retrieved again.
+func (s *Service) Create(ctx context.Context, name string, scopes []string) (*domain.APIKey, string, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, "", domain.ErrUserNotAuthenticated
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxNameLength {
+		return nil, "", domain.ErrInvalidAPIKeyName
+	}
+	if len(scopes) == 0 {
+		return nil, "", domain.ErrInvalidAPIKeyScope
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(domain.APIKeyScopes, scope) {
+			return nil, "", domain.ErrInvalidAPIKeyScope
+		}
+	}
+
+	prefix, secret, err := newKey()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate api key", "error", err)
+		return nil, "", err
+	}
+	raw := keyPrefix + prefix + "_" + secret
+
+	key := &domain.APIKey{
+		UserID:  uid,
+		Name:    name,
+		Prefix:  prefix,
+		KeyHash: hashKey(raw),
+		Scopes:  slices.Compact(slices.Sorted(slices.Values(scopes))),
+	}
+	if err := s.repo.Save(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when saving api key", "userID", uid, "error", err)
+		return nil, "", err
+	}
+	slog.InfoContext(ctx, "api key created", "userID", uid, "keyID", key.ID, "scopes", key.Scopes)
+	return key, raw, nil
+}
+
+func (s *Service) List(ctx context.Context) ([]*domain.APIKey, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	keys, err := s.repo.ListByUser(ctx, uid)
+	if err != nil {
+		slog.ErrorContext(ctx, "unknown db error when listing api keys", "userID", uid, "error", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (s *Service) Revoke(ctx context.Context, id string) error {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return domain.ErrUserNotAuthenticated
+	}
+	if err := s.repo.Revoke(ctx, id, uid); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrAPIKeyNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when revoking api key", "userID", uid, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "api key revoked", "userID", uid, "keyID", id)
+	return nil
+}
+
+func (s *Service) Authenticate(ctx context.Context, raw string) (*domain.APIKey, error) {
+	if !strings.HasPrefix(raw, keyPrefix) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	key, err := s.repo.Use(ctx, hashKey(raw))
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func newKey() (string, string, error) {
+	b := make([]byte, prefixBytes+secretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return hex.EncodeToString(b[:prefixBytes]), base64.RawURLEncoding.EncodeToString(b[prefixBytes:]), nil
+}
+
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
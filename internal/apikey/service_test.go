@@ -0,0 +1,119 @@
+package apikey_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/apikey"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+)
+
+func TestService_Create(t *testing.T) {
+	tests := []struct {
+		name      string
+		userID    string
+		keyName   string
+		scopes    []string
+		repoError bool
+		wantErr   error
+	}{
+		{name: "success", userID: "user-1", keyName: "ci", scopes: []string{domain.ScopeLinksWrite}},
+		{name: "anonymous", userID: "", keyName: "ci", scopes: []string{domain.ScopeLinksWrite}, wantErr: domain.ErrUserNotAuthenticated},
+		{name: "empty name", userID: "user-1", keyName: "  ", scopes: []string{domain.ScopeLinksWrite}, wantErr: domain.ErrInvalidAPIKeyName},
+		{name: "long name", userID: "user-1", keyName: strings.Repeat("a", 65), scopes: []string{domain.ScopeLinksWrite}, wantErr: domain.ErrInvalidAPIKeyName},
+		{name: "no scopes", userID: "user-1", keyName: "ci", wantErr: domain.ErrInvalidAPIKeyScope},
+		{name: "unknown scope", userID: "user-1", keyName: "ci", scopes: []string{"links:admin"}, wantErr: domain.ErrInvalidAPIKeyScope},
+		{name: "keys:manage not grantable", userID: "user-1", keyName: "ci", scopes: []string{domain.ScopeKeysManage}, wantErr: domain.ErrInvalidAPIKeyScope},
+		{name: "repository error", userID: "user-1", keyName: "ci", scopes: []string{domain.ScopeLinksRead}, repoError: true, wantErr: ErrMockedError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRepository{shouldError: tt.repoError}
+			svc := apikey.NewService(mock)
+			ctx := identity.WithUserID(context.Background(), tt.userID)
+
+			key, raw, err := svc.Create(ctx, tt.keyName, tt.scopes)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected error %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasPrefix(raw, "shk_"+key.Prefix+"_") {
+				t.Errorf("expected raw key to start with prefix %q, got %q", key.Prefix, raw)
+			}
+			if len(key.Prefix) != 8 {
+				t.Errorf("expected an 8 character prefix, got %q", key.Prefix)
+			}
+			if secret, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(raw, "shk_"+key.Prefix+"_")); err != nil || len(secret) != 32 {
+				t.Errorf("expected a 32 byte secret, got %d bytes (err %v)", len(secret), err)
+			}
+			if key.KeyHash == "" || strings.Contains(key.KeyHash, raw) {
+				t.Error("expected key to be stored hashed")
+			}
+		})
+	}
+}
+
+func TestService_Authenticate(t *testing.T) {
+	mock := &MockRepository{}
+	svc := apikey.NewService(mock)
+	ctx := identity.WithUserID(context.Background(), "user-1")
+
+	key, raw, err := svc.Create(ctx, "ci", []string{domain.ScopeLinksWrite})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := svc.Authenticate(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.UserID != "user-1" {
+		t.Errorf("expected user-1, got %q", got.UserID)
+	}
+	if got.LastUsedAt == nil {
+		t.Error("expected last used timestamp to be recorded")
+	}
+
+	if _, err := svc.Authenticate(context.Background(), "shk_nope"); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("expected %v, got %v", domain.ErrInvalidAPIKey, err)
+	}
+
+	if err := svc.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Authenticate(context.Background(), raw); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestService_Revoke(t *testing.T) {
+	mock := &MockRepository{}
+	svc := apikey.NewService(mock)
+	owner := identity.WithUserID(context.Background(), "user-1")
+	other := identity.WithUserID(context.Background(), "user-2")
+
+	key, _, err := svc.Create(owner, "ci", []string{domain.ScopeLinksRead})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := svc.Revoke(other, key.ID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrAPIKeyNotFound, err)
+	}
+	if err := svc.Revoke(owner, key.ID); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := svc.Revoke(owner, key.ID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrAPIKeyNotFound, err)
+	}
+}
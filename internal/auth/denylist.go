@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	denylistPrefix     = "jwt:deny:"
+	denylistUserPrefix = "jwt:deny:user:"
+)
+
+type Denylist interface {
+	Deny(ctx context.Context, tokenID string, ttl time.Duration) error
+	IsDenied(ctx context.Context, tokenID string) (bool, error)
+	// DenyUser denies every token of the user issued before the call. ttl should cover
+	// the lifetime of the longest lived token.
+	DenyUser(ctx context.Context, userID string, ttl time.Duration) error
+	IsUserDenied(ctx context.Context, userID string, issuedAt time.Time) (bool, error)
+}
+
+type RedisDenylist struct {
+	client *redis.Client
+}
+
+func NewRedisDenylist(client *redis.Client) *RedisDenylist {
+	return &RedisDenylist{client: client}
+}
+
+func (d *RedisDenylist) Deny(ctx context.Context, tokenID string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	if err := d.client.Set(ctx, denylistPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("redis set error: %w", err)
+	}
+	return nil
+}
+
+func (d *RedisDenylist) IsDenied(ctx context.Context, tokenID string) (bool, error) {
+	err := d.client.Get(ctx, denylistPrefix+tokenID).Err()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unexpected error when getting from redis: %w", err)
+	}
+	return true, nil
+}
+
+func (d *RedisDenylist) DenyUser(ctx context.Context, userID string, ttl time.Duration) error {
+	if err := d.client.Set(ctx, denylistUserPrefix+userID, time.Now().Unix(), ttl).Err(); err != nil {
+		return fmt.Errorf("redis set error: %w", err)
+	}
+	return nil
+}
+
+// IsUserDenied compares at second precision, the precision of the iat claim, so tokens
+// issued within the same second as the revocation remain valid.
+func (d *RedisDenylist) IsUserDenied(ctx context.Context, userID string, issuedAt time.Time) (bool, error) {
+	deniedBefore, err := d.client.Get(ctx, denylistUserPrefix+userID).Int64()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		return false, fmt.Errorf("unexpected error when getting from redis: %w", err)
+	}
+	return issuedAt.Unix() < deniedBefore, nil
+}
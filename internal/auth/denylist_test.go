@@ -0,0 +1,63 @@
+package auth_test
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRedisDenylist(t *testing.T) {
+	denylist, mr := newTestDenylist(t)
+	ctx := context.Background()
+
+	if denied, err := denylist.IsDenied(ctx, "jti-1"); err != nil || denied {
+		t.Fatalf("expected fresh token not to be denied, got denied=%v err=%v", denied, err)
+	}
+
+	if err := denylist.Deny(ctx, "jti-1", time.Minute); err != nil {
+		t.Fatalf("Deny() error = %v", err)
+	}
+	if denied, err := denylist.IsDenied(ctx, "jti-1"); err != nil || !denied {
+		t.Fatalf("expected token to be denied, got denied=%v err=%v", denied, err)
+	}
+
+	mr.FastForward(2 * time.Minute)
+	if denied, _ := denylist.IsDenied(ctx, "jti-1"); denied {
+		t.Error("expected denylist entry to expire")
+	}
+
+	if err := denylist.Deny(ctx, "jti-2", 0); err != nil {
+		t.Fatalf("Deny() error = %v", err)
+	}
+	if denied, _ := denylist.IsDenied(ctx, "jti-2"); denied {
+		t.Error("expected already expired tokens not to be stored")
+	}
+}
+
+func TestRedisDenylist_DenyUser(t *testing.T) {
+	denylist, mr := newTestDenylist(t)
+	ctx := context.Background()
+	before := time.Now().Add(-time.Minute)
+
+	if denied, err := denylist.IsUserDenied(ctx, "user-1", before); err != nil || denied {
+		t.Fatalf("expected user not to be denied, got denied=%v err=%v", denied, err)
+	}
+
+	if err := denylist.DenyUser(ctx, "user-1", time.Hour); err != nil {
+		t.Fatalf("DenyUser() error = %v", err)
+	}
+	if denied, err := denylist.IsUserDenied(ctx, "user-1", before); err != nil || !denied {
+		t.Errorf("expected older token to be denied, got denied=%v err=%v", denied, err)
+	}
+	if denied, _ := denylist.IsUserDenied(ctx, "user-1", time.Now().Add(time.Second)); denied {
+		t.Error("expected tokens issued after the revocation to be accepted")
+	}
+	if denied, _ := denylist.IsUserDenied(ctx, "user-2", before); denied {
+		t.Error("expected other users not to be denied")
+	}
+
+	mr.FastForward(2 * time.Hour)
+	if denied, _ := denylist.IsUserDenied(ctx, "user-1", before); denied {
+		t.Error("expected user revocation to expire")
+	}
+}
@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	guardFailPrefix = "login:fail:"
+	guardLockPrefix = "login:lock:"
+)
+
+// LoginGuard throttles password guessing. Failures are tracked per nickname and per client
+// address whether or not the nickname exists, so lockouts do not reveal registered accounts.
+type LoginGuard interface {
+	// Locked returns how long logins for the nickname or from the address are still blocked.
+	Locked(ctx context.Context, nickname string, ip string) (time.Duration, error)
+	// Fail records a failed attempt and returns the lockout it triggered, if any.
+	Fail(ctx context.Context, nickname string, ip string) (time.Duration, error)
+	// Reset forgets the failures of a nickname after a successful login. Address counters
+	// are kept so an attacker cannot clear them by logging into their own account.
+	Reset(ctx context.Context, nickname string) error
+}
+
+// LockoutError is returned while logins are blocked.
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", domain.ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Unwrap() error {
+	return domain.ErrTooManyLoginAttempts
+}
+
+type GuardConfig struct {
+	// MaxAttempts is how many failures per nickname are tolerated before backing off.
+	MaxAttempts int
+	// MaxAttemptsPerIP is the same for a client address, higher since addresses are shared.
+	MaxAttemptsPerIP int
+	// BaseDelay is the first lockout. It doubles with every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// KEYS: failure counter, lock; ARGV: window (ms), threshold, base delay (ms), max delay (ms).
+// Returns the lockout applied in ms, 0 when below the threshold.
+var recordFailure = redis.NewScript(`
+local failures = redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+local threshold = tonumber(ARGV[2])
+if failures < threshold then
+	return 0
+end
+local delay = math.min(tonumber(ARGV[3]) * 2 ^ (failures - threshold), tonumber(ARGV[4]))
+delay = math.floor(delay)
+redis.call('SET', KEYS[2], 1, 'PX', delay)
+return delay
+`)
+
+type RedisLoginGuard struct {
+	client *redis.Client
+	cfg    GuardConfig
+}
+
+func NewRedisLoginGuard(client *redis.Client, cfg GuardConfig) *RedisLoginGuard {
+	return &RedisLoginGuard{client: client, cfg: cfg}
+}
+
+func (g *RedisLoginGuard) Locked(ctx context.Context, nickname string, ip string) (time.Duration, error) {
+	pipe := g.client.Pipeline()
+	byNickname := pipe.PTTL(ctx, guardLockPrefix+nicknameSubject(nickname))
+	byIP := pipe.PTTL(ctx, guardLockPrefix+ipSubject(ip))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("redis pttl error: %w", err)
+	}
+	// PTTL is negative for missing keys
+	return max(byNickname.Val(), byIP.Val(), 0), nil
+}
+
+func (g *RedisLoginGuard) Fail(ctx context.Context, nickname string, ip string) (time.Duration, error) {
+	nicknameDelay, err := g.fail(ctx, nicknameSubject(nickname), g.cfg.MaxAttempts)
+	if err != nil {
+		return 0, err
+	}
+	ipDelay, err := g.fail(ctx, ipSubject(ip), g.cfg.MaxAttemptsPerIP)
+	if err != nil {
+		return 0, err
+	}
+	return max(nicknameDelay, ipDelay), nil
+}
+
+func (g *RedisLoginGuard) Reset(ctx context.Context, nickname string) error {
+	subject := nicknameSubject(nickname)
+	if err := g.client.Del(ctx, guardFailPrefix+subject, guardLockPrefix+subject).Err(); err != nil {
+		return fmt.Errorf("redis del error: %w", err)
+	}
+	return nil
+}
+
+func (g *RedisLoginGuard) fail(ctx context.Context, subject string, threshold int) (time.Duration, error) {
+	delay, err := recordFailure.Run(ctx, g.client,
+		[]string{guardFailPrefix + subject, guardLockPrefix + subject},
+		g.cfg.Window.Milliseconds(), threshold, g.cfg.BaseDelay.Milliseconds(), g.cfg.MaxDelay.Milliseconds(),
+	).Int64()
+	if err != nil {
+		return 0, fmt.Errorf("login guard script failed: %w", err)
+	}
+	return time.Duration(delay) * time.Millisecond, nil
+}
+
+func nicknameSubject(nickname string) string {
+	return "nick:" + strings.ToLower(nickname)
+}
+
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,102 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/jwt"
+	"github.com/redis/go-redis/v9"
+)
+
+var testGuardConfig = auth.GuardConfig{
+	MaxAttempts:      3,
+	MaxAttemptsPerIP: 5,
+	BaseDelay:        time.Minute,
+	MaxDelay:         10 * time.Minute,
+	Window:           time.Hour,
+}
+
+func newTestGuard(t *testing.T) (*auth.RedisLoginGuard, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return auth.NewRedisLoginGuard(redis.NewClient(&redis.Options{Addr: mr.Addr()}), testGuardConfig), mr
+}
+
+func TestRedisLoginGuard(t *testing.T) {
+	guard, mr := newTestGuard(t)
+	ctx := context.Background()
+
+	fail := func(nickname, ip string) time.Duration {
+		t.Helper()
+		lockout, err := guard.Fail(ctx, nickname, ip)
+		if err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+		return lockout
+	}
+
+	if fail("enzo", "1.1.1.1") != 0 || fail("Enzo", "2.2.2.2") != 0 {
+		t.Fatal("expected no lockout below the threshold")
+	}
+	if got := fail("ENZO", "3.3.3.3"); got != time.Minute {
+		t.Fatalf("expected base lockout once the threshold is reached, got %s", got)
+	}
+	if locked, _ := guard.Locked(ctx, "enzo", "9.9.9.9"); locked != time.Minute {
+		t.Errorf("expected nickname to be locked from any address, got %s", locked)
+	}
+	if got := fail("enzo", "4.4.4.4"); got != 2*time.Minute {
+		t.Errorf("expected lockout to double, got %s", got)
+	}
+	for range 5 {
+		fail("enzo", "5.5.5.5")
+	}
+	if got := fail("enzo", "5.5.5.5"); got != 10*time.Minute {
+		t.Errorf("expected lockout to be capped, got %s", got)
+	}
+	if locked, _ := guard.Locked(ctx, "someone", "5.5.5.5"); locked == 0 {
+		t.Error("expected address to be locked for every nickname")
+	}
+
+	mr.FastForward(11 * time.Minute)
+	if locked, _ := guard.Locked(ctx, "enzo", "1.1.1.1"); locked != 0 {
+		t.Errorf("expected lockout to expire, got %s", locked)
+	}
+
+	if err := guard.Reset(ctx, "enzo"); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if got := fail("enzo", "6.6.6.6"); got != 0 {
+		t.Errorf("expected reset to clear nickname failures, got %s", got)
+	}
+}
+
+func TestService_Authenticate_Lockout(t *testing.T) {
+	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash}}}
+	denylist, _ := newTestDenylist(t)
+	guard, _ := newTestGuard(t)
+	svc := auth.NewService(repo, jwt.NewManager("secret-key-test", time.Hour), denylist, guard, time.Hour)
+	ctx := context.Background()
+
+	// unknown nicknames are locked out the same way, so lockouts do not reveal accounts
+	for nickname, ip := range map[string]string{"enzo": "1.1.1.1", "ghost": "3.3.3.3"} {
+		for range testGuardConfig.MaxAttempts {
+			if _, err := svc.Authenticate(ctx, nickname, "wrong_password", ip); errors.Is(err, domain.ErrTooManyLoginAttempts) {
+				t.Fatalf("%s: locked out before the threshold", nickname)
+			}
+		}
+
+		_, err := svc.Authenticate(ctx, nickname, "valid_password", "2.2.2.2")
+		var lockout *auth.LockoutError
+		if !errors.As(err, &lockout) || lockout.RetryAfter != time.Minute {
+			t.Fatalf("%s: expected lockout even with the right password, got %v", nickname, err)
+		}
+		if !errors.Is(err, domain.ErrTooManyLoginAttempts) {
+			t.Errorf("%s: expected lockout to wrap %v", nickname, domain.ErrTooManyLoginAttempts)
+		}
+	}
+}
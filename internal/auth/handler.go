@@ -3,11 +3,16 @@ package auth
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/fernandesenzo/shortener/internal/clientip"
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/problem"
 )
 
 type Handler struct {
@@ -21,7 +26,7 @@ func NewHandler(srv *Service) *Handler {
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
-		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		problem.UnsupportedMediaType(w, r)
 		return
 	}
 
@@ -29,21 +34,72 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		problem.InvalidBody(w, r)
 		return
 	}
 
-	token, err := h.srv.Authenticate(r.Context(), req.Nickname, req.Password)
+	tokens, err := h.srv.Authenticate(r.Context(), req.Nickname, req.Password, clientip.FromRequest(r))
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidPassword) || errors.Is(err, domain.ErrNicknameNotFound) {
-			http.Error(w, "invalid credentials", http.StatusUnauthorized)
-			return
+		var lockout *LockoutError
+		if errors.As(err, &lockout) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(lockout.RetryAfter.Seconds()))))
 		}
-		http.Error(w, "internal error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
-	resp := &loginResponse{Token: token}
+	h.sendTokens(w, tokens)
+}
+
+func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req refreshRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil || req.RefreshToken == "" {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	tokens, err := h.srv.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	h.sendTokens(w, tokens)
+}
+
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req logoutRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	// api keys have no session to end and are revoked through their own endpoint
+	accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || r.Header.Get("X-API-Key") != "" {
+		problem.Error(w, r, domain.ErrLogoutRequiresAccessToken)
+		return
+	}
+	if err := h.srv.Logout(r.Context(), accessToken, req.RefreshToken); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) sendTokens(w http.ResponseWriter, tokens *TokenPair) {
+	resp := &loginResponse{Token: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
@@ -8,9 +8,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/jwt"
 	"github.com/fernandesenzo/shortener/internal/testutil"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -32,7 +35,9 @@ func TestHandlerLogin(t *testing.T) {
 
 	repo := auth.NewPostgresRepository(db)
 	jwtManager := jwt.NewManager("test-secret", time.Hour)
-	service := auth.NewService(repo, jwtManager)
+	mr := miniredis.RunT(t)
+	denylist := auth.NewRedisDenylist(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+	service := auth.NewService(repo, jwtManager, denylist, nil, time.Hour)
 	handler := auth.NewHandler(service)
 
 	tests := []struct {
@@ -47,42 +52,42 @@ func TestHandlerLogin(t *testing.T) {
 			reqBody:        `{"nickname": "enzo", "password": "secret123"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusOK,
-			expectedInBody: `"token":`,
+			expectedInBody: `"refreshToken":`,
 		},
 		{
 			name:           "Invalid Password",
 			reqBody:        `{"nickname": "enzo", "password": "wrongpassword"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusUnauthorized,
-			expectedInBody: "invalid credentials",
+			expectedInBody: `"code":"invalid_credentials"`,
 		},
 		{
 			name:           "User Not Found",
 			reqBody:        `{"nickname": "ghost", "password": "secret123"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusUnauthorized,
-			expectedInBody: "invalid credentials",
+			expectedInBody: `"code":"invalid_credentials"`,
 		},
 		{
 			name:           "Invalid Content-Type",
 			reqBody:        `{"nickname": "enzo", "password": "secret123"}`,
 			contentType:    "text/plain",
 			expectedStatus: http.StatusUnsupportedMediaType,
-			expectedInBody: "unsupported content type",
+			expectedInBody: `"code":"unsupported_media_type"`,
 		},
 		{
 			name:           "Invalid JSON Format",
 			reqBody:        `{"nickname": "enzo", "password":`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid request",
+			expectedInBody: `"code":"invalid_request_body"`,
 		},
 		{
 			name:           "Unknown Fields",
 			reqBody:        `{"nickname": "enzo", "password": "secret123", "admin": true}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid request",
+			expectedInBody: `"code":"invalid_request_body"`,
 		},
 	}
 
@@ -106,3 +111,92 @@ func TestHandlerLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerLogin_Lockout(t *testing.T) {
+	denylist, _ := newTestDenylist(t)
+	guard, _ := newTestGuard(t)
+	service := auth.NewService(&MockRepository{}, jwt.NewManager("test-secret", time.Hour), denylist, guard, time.Hour)
+	handler := auth.NewHandler(service)
+
+	login := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/login", strings.NewReader(`{"nickname": "ghost", "password": "secret123"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		handler.Login(rr, req)
+		return rr
+	}
+
+	for range testGuardConfig.MaxAttempts {
+		if rr := login(); rr.Code != http.StatusUnauthorized {
+			t.Fatalf("expected 401 before the threshold, got %d", rr.Code)
+		}
+	}
+
+	rr := login()
+	if rr.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", rr.Code)
+	}
+	if got := rr.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("expected Retry-After 60, got %q", got)
+	}
+	if !strings.Contains(rr.Body.String(), `"code":"too_many_login_attempts"`) {
+		t.Errorf("expected too_many_login_attempts code, got %s", rr.Body.String())
+	}
+}
+
+func TestHandlerLogout(t *testing.T) {
+	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash}}}
+	denylist, _ := newTestDenylist(t)
+	service := auth.NewService(repo, jwt.NewManager("test-secret", time.Hour), denylist, nil, time.Hour)
+	handler := auth.NewHandler(service)
+
+	tokens, err := service.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		headers        map[string]string
+		expectedStatus int
+		expectedInBody string
+	}{
+		{
+			name:           "access token",
+			headers:        map[string]string{"Authorization": "Bearer " + tokens.AccessToken},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "api key in authorization header",
+			headers:        map[string]string{"Authorization": "ApiKey shk_abcd1234_secret"},
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"logout_requires_access_token"`,
+		},
+		{
+			name:           "api key header",
+			headers:        map[string]string{"X-API-Key": "shk_abcd1234_secret"},
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"logout_requires_access_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/logout", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.Logout(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if !strings.Contains(rr.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %s, got %s", tt.expectedInBody, rr.Body.String())
+			}
+		})
+	}
+}
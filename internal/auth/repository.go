@@ -8,7 +8,16 @@ import (
 )
 
 var ErrRecordNotFound = errors.New("nickname does not exist")
+var ErrTokenNotFound = errors.New("refresh token does not exist")
+var ErrTokenAlreadyRevoked = errors.New("refresh token already revoked")
 
 type Repository interface {
 	GetByNickname(ctx context.Context, nickname string) (*domain.User, error)
+	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	UpdatePasswordHash(ctx context.Context, userID string, passwordHash string) error
+	SaveRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(ctx context.Context, id string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
 }
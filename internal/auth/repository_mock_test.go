@@ -3,6 +3,8 @@ package auth_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/auth"
 	"github.com/fernandesenzo/shortener/internal/domain"
@@ -11,8 +13,9 @@ import (
 var ErrMockedRepo = errors.New("forced mockdb error")
 
 type MockRepository struct {
-	users       []*domain.User
-	shouldError bool
+	users         []*domain.User
+	refreshTokens []*domain.RefreshToken
+	shouldError   bool
 }
 
 func (m *MockRepository) GetByNickname(ctx context.Context, nickname string) (*domain.User, error) {
@@ -27,3 +30,107 @@ func (m *MockRepository) GetByNickname(ctx context.Context, nickname string) (*d
 	}
 	return nil, auth.ErrRecordNotFound
 }
+
+func (m *MockRepository) GetByID(_ context.Context, userID string) (*domain.User, error) {
+	if m.shouldError {
+		return nil, ErrMockedRepo
+	}
+
+	for _, u := range m.users {
+		if u.ID == userID {
+			return u, nil
+		}
+	}
+	return nil, auth.ErrRecordNotFound
+}
+
+func (m *MockRepository) UpdatePasswordHash(_ context.Context, userID string, passwordHash string) error {
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	for _, u := range m.users {
+		if u.ID == userID {
+			u.PasswordHash = passwordHash
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) SaveRefreshToken(_ context.Context, token *domain.RefreshToken) error {
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	token.ID = fmt.Sprintf("token-%d", len(m.refreshTokens)+1)
+	if token.FamilyID == "" {
+		token.FamilyID = "family-" + token.ID
+	}
+	token.CreatedAt = time.Now()
+	m.refreshTokens = append(m.refreshTokens, token)
+	return nil
+}
+
+func (m *MockRepository) GetRefreshToken(_ context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	if m.shouldError {
+		return nil, ErrMockedRepo
+	}
+	for _, t := range m.refreshTokens {
+		if t.TokenHash == tokenHash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, auth.ErrTokenNotFound
+}
+
+func (m *MockRepository) RevokeRefreshToken(_ context.Context, id string) error {
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	for _, t := range m.refreshTokens {
+		if t.ID == id {
+			if t.RevokedAt != nil {
+				return auth.ErrTokenAlreadyRevoked
+			}
+			now := time.Now()
+			t.RevokedAt = &now
+			return nil
+		}
+	}
+	return auth.ErrTokenAlreadyRevoked
+}
+
+func (m *MockRepository) RevokeRefreshTokenFamily(_ context.Context, familyID string) error {
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	now := time.Now()
+	for _, t := range m.refreshTokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) RevokeUserRefreshTokens(_ context.Context, userID string) error {
+	if m.shouldError {
+		return ErrMockedRepo
+	}
+	now := time.Now()
+	for _, t := range m.refreshTokens {
+		if t.UserID == userID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) activeTokens() int {
+	active := 0
+	for _, t := range m.refreshTokens {
+		if t.RevokedAt == nil {
+			active++
+		}
+	}
+	return active
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 )
@@ -15,15 +16,28 @@ type PostgresRepository struct {
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
 	return &PostgresRepository{db: db}
 }
+
 func (r PostgresRepository) GetByNickname(ctx context.Context, nickname string) (*domain.User, error) {
-	query := `SELECT id, nickname, password_hash, created_at FROM users WHERE nickname = $1`
+	query := `SELECT id, nickname, password_hash, role, created_at, disabled_at FROM users WHERE nickname = $1`
+	return r.getUser(ctx, query, nickname)
+}
+
+func (r PostgresRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	query := `SELECT id, nickname, password_hash, role, created_at, disabled_at FROM users WHERE id = $1`
+	return r.getUser(ctx, query, userID)
+}
+
+func (r PostgresRepository) getUser(ctx context.Context, query string, arg string) (*domain.User, error) {
 	var user domain.User
-	row := r.db.QueryRowContext(ctx, query, nickname)
+	var disabledAt sql.NullTime
+	row := r.db.QueryRowContext(ctx, query, arg)
 	err := row.Scan(
 		&user.ID,
 		&user.Nickname,
 		&user.PasswordHash,
+		&user.Role,
 		&user.CreatedAt,
+		&disabledAt,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -31,5 +45,89 @@ func (r PostgresRepository) GetByNickname(ctx context.Context, nickname string)
 		}
 		return nil, err
 	}
+	if disabledAt.Valid {
+		user.DisabledAt = &disabledAt.Time
+	}
 	return &user, nil
 }
+
+func (r PostgresRepository) UpdatePasswordHash(ctx context.Context, userID string, passwordHash string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("error updating password hash: %w", err)
+	}
+	return nil
+}
+
+// SaveRefreshToken starts a new token family when token.FamilyID is empty.
+func (r PostgresRepository) SaveRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	query := `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at)
+        VALUES ($1, COALESCE(NULLIF($2, '')::uuid, gen_random_uuid()), $3, $4)
+        RETURNING id, family_id, created_at`
+	err := r.db.QueryRowContext(ctx, query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt).
+		Scan(&token.ID, &token.FamilyID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving refresh token: %w", err)
+	}
+	return nil
+}
+
+func (r PostgresRepository) GetRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	query := `
+        SELECT id, user_id, family_id, token_hash, created_at, expires_at, revoked_at
+        FROM refresh_tokens WHERE token_hash = $1`
+	var token domain.RefreshToken
+	var revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.FamilyID,
+		&token.TokenHash,
+		&token.CreatedAt,
+		&token.ExpiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTokenNotFound
+		}
+		return nil, fmt.Errorf("error getting refresh token: %w", err)
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken only succeeds for the first caller, which makes concurrent reuse detectable.
+func (r PostgresRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh token: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrTokenAlreadyRevoked
+	}
+	return nil
+}
+
+func (r PostgresRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+	if err != nil {
+		return fmt.Errorf("error revoking refresh token family: %w", err)
+	}
+	return nil
+}
+
+func (r PostgresRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("error revoking user refresh tokens: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/auth"
+	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/testutil"
 )
 
@@ -39,6 +41,17 @@ func TestPostgresRepository_GetByNickname(t *testing.T) {
 		if user.PasswordHash != passwordHash {
 			t.Errorf("expected password hash %s, got %s", passwordHash, user.PasswordHash)
 		}
+		if user.Role != domain.RoleUser || user.Disabled() {
+			t.Errorf("expected an enabled user with the default role, got %+v", user)
+		}
+
+		byID, err := repo.GetByID(ctx, user.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if byID.Nickname != nickname {
+			t.Errorf("expected nickname %s, got %s", nickname, byID.Nickname)
+		}
 	})
 
 	t.Run("should return ErrRecordNotFound when nickname does not exist", func(t *testing.T) {
@@ -52,3 +65,70 @@ func TestPostgresRepository_GetByNickname(t *testing.T) {
 		}
 	})
 }
+
+func TestPostgresRepository_RefreshTokens(t *testing.T) {
+	db, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	repo := auth.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	var userID string
+	err := db.QueryRowContext(ctx, `INSERT INTO users (nickname, password_hash) VALUES ('refresh_user', 'hash') RETURNING id`).Scan(&userID)
+	if err != nil {
+		t.Fatalf("failed to insert test user: %v", err)
+	}
+
+	first := &domain.RefreshToken{UserID: userID, TokenHash: "hash-1", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.SaveRefreshToken(ctx, first); err != nil {
+		t.Fatalf("SaveRefreshToken() error = %v", err)
+	}
+	if first.ID == "" || first.FamilyID == "" {
+		t.Fatalf("expected generated id and family, got %+v", first)
+	}
+
+	second := &domain.RefreshToken{UserID: userID, FamilyID: first.FamilyID, TokenHash: "hash-2", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.SaveRefreshToken(ctx, second); err != nil {
+		t.Fatalf("SaveRefreshToken() error = %v", err)
+	}
+	if second.FamilyID != first.FamilyID {
+		t.Errorf("expected family %s, got %s", first.FamilyID, second.FamilyID)
+	}
+
+	if err := repo.RevokeRefreshToken(ctx, first.ID); err != nil {
+		t.Fatalf("RevokeRefreshToken() error = %v", err)
+	}
+	if err := repo.RevokeRefreshToken(ctx, first.ID); !errors.Is(err, auth.ErrTokenAlreadyRevoked) {
+		t.Errorf("expected %v on second revoke, got %v", auth.ErrTokenAlreadyRevoked, err)
+	}
+
+	if err := repo.RevokeRefreshTokenFamily(ctx, first.FamilyID); err != nil {
+		t.Fatalf("RevokeRefreshTokenFamily() error = %v", err)
+	}
+	got, err := repo.GetRefreshToken(ctx, "hash-2")
+	if err != nil {
+		t.Fatalf("GetRefreshToken() error = %v", err)
+	}
+	if got.RevokedAt == nil {
+		t.Error("expected family revocation to revoke the second token")
+	}
+
+	third := &domain.RefreshToken{UserID: userID, TokenHash: "hash-3", ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.SaveRefreshToken(ctx, third); err != nil {
+		t.Fatalf("SaveRefreshToken() error = %v", err)
+	}
+	if err := repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		t.Fatalf("RevokeUserRefreshTokens() error = %v", err)
+	}
+	got, err = repo.GetRefreshToken(ctx, "hash-3")
+	if err != nil {
+		t.Fatalf("GetRefreshToken() error = %v", err)
+	}
+	if got.RevokedAt == nil {
+		t.Error("expected user revocation to revoke every token")
+	}
+
+	if _, err := repo.GetRefreshToken(ctx, "missing"); !errors.Is(err, auth.ErrTokenNotFound) {
+		t.Errorf("expected %v, got %v", auth.ErrTokenNotFound, err)
+	}
+}
@@ -2,42 +2,254 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/jwt"
 	"github.com/fernandesenzo/shortener/internal/password"
 )
 
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
+
 type Service struct {
 	repo       Repository
 	jwtManager *jwt.Manager
+	denylist   Denylist
+	guard      LoginGuard
+	refreshTTL time.Duration
 }
 
-func NewService(repo Repository, jwtManager *jwt.Manager) *Service {
-	return &Service{repo: repo, jwtManager: jwtManager}
+// NewService returns the auth service. A nil guard disables login throttling.
+func NewService(repo Repository, jwtManager *jwt.Manager, denylist Denylist, guard LoginGuard, refreshTTL time.Duration) *Service {
+	return &Service{repo: repo, jwtManager: jwtManager, denylist: denylist, guard: guard, refreshTTL: refreshTTL}
 }
 
-func (s *Service) Authenticate(ctx context.Context, nickname string, pswd string) (string, error) {
+// Authenticate checks the credentials of a login attempt coming from ip. Once too many
+// attempts failed, it returns a *LockoutError without looking at the password.
+func (s *Service) Authenticate(ctx context.Context, nickname string, pswd string, ip string) (*TokenPair, error) {
+	if s.guard != nil {
+		locked, err := s.guard.Locked(ctx, nickname, ip)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to check login lockout", "error", err)
+		} else if locked > 0 {
+			slog.WarnContext(ctx, "login attempt while locked out", "event", "auth.login_blocked", "nickname", nickname, "ip", ip, "retryAfter", locked.String())
+			return nil, &LockoutError{RetryAfter: locked}
+		}
+	}
+
 	user, err := s.repo.GetByNickname(ctx, nickname)
 	if err != nil {
 		if errors.Is(err, ErrRecordNotFound) {
 			password.CompareDummy(pswd)
-			return "", domain.ErrNicknameNotFound
+			s.recordFailure(ctx, nickname, ip)
+			return nil, domain.ErrNicknameNotFound
 		}
 		slog.ErrorContext(ctx, "unknown db error when trying to get user by nickname", "nickname", nickname, "error", err)
-		return "", err
+		return nil, err
 	}
 
 	if err := password.Compare(user.PasswordHash, pswd); err != nil {
-		return "", domain.ErrInvalidPassword
+		s.recordFailure(ctx, nickname, ip)
+		return nil, domain.ErrInvalidPassword
+	}
+	if user.Disabled() {
+		slog.WarnContext(ctx, "login attempt on disabled account", "event", "auth.login_disabled", "userID", user.ID)
+		return nil, domain.ErrAccountDisabled
+	}
+
+	if s.guard != nil {
+		if err := s.guard.Reset(ctx, nickname); err != nil {
+			slog.ErrorContext(ctx, "failed to reset login failures", "error", err)
+		}
+	}
+	s.rehash(ctx, user, pswd)
+	return s.issueTokens(ctx, user, "")
+}
+
+// rehash upgrades a hash made with an outdated algorithm or parameters while the plain
+// password is at hand. Failures only postpone the upgrade to the next login.
+func (s *Service) rehash(ctx context.Context, user *domain.User, pswd string) {
+	if !password.NeedsRehash(user.PasswordHash) {
+		return
+	}
+	hashed, err := password.Hash(pswd)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to rehash password", "userID", user.ID, "error", err)
+		return
+	}
+	if err := s.repo.UpdatePasswordHash(ctx, user.ID, hashed); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when saving rehashed password", "userID", user.ID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "password hash upgraded", "userID", user.ID)
+}
+
+func (s *Service) recordFailure(ctx context.Context, nickname string, ip string) {
+	slog.WarnContext(ctx, "login failed", "event", "auth.login_failed", "nickname", nickname, "ip", ip)
+	if s.guard == nil {
+		return
+	}
+	lockout, err := s.guard.Fail(ctx, nickname, ip)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record login failure", "error", err)
+		return
+	}
+	if lockout > 0 {
+		slog.WarnContext(ctx, "login locked out", "event", "auth.login_locked", "nickname", nickname, "ip", ip, "duration", lockout.String())
+	}
+}
+
+// Refresh rotates a refresh token. Presenting an already rotated token revokes its whole family,
+// since it means the token was stolen either by the current holder or by the one who rotated it.
+func (s *Service) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	stored, err := s.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting refresh token", "error", err)
+		return nil, err
+	}
+
+	if stored.RevokedAt != nil {
+		return nil, s.handleReuse(ctx, stored)
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
 	}
 
-	token, err := s.jwtManager.GenerateToken(user.ID)
+	// the role may have changed since the last token was issued, so it is read again
+	user, err := s.repo.GetByID(ctx, stored.UserID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting user by id", "userID", stored.UserID, "error", err)
+		return nil, err
+	}
+	if user.Disabled() {
+		return nil, domain.ErrAccountDisabled
+	}
+
+	if err := s.repo.RevokeRefreshToken(ctx, stored.ID); err != nil {
+		if errors.Is(err, ErrTokenAlreadyRevoked) {
+			return nil, s.handleReuse(ctx, stored)
+		}
+		slog.ErrorContext(ctx, "unknown db error when revoking refresh token", "error", err)
+		return nil, err
+	}
+
+	return s.issueTokens(ctx, user, stored.FamilyID)
+}
+
+// Logout denies the access token until it is no longer accepted and, when given, revokes the refresh token family.
+func (s *Service) Logout(ctx context.Context, accessToken string, refreshToken string) error {
+	claims, err := s.jwtManager.ValidateToken(accessToken)
+	if err != nil {
+		return domain.ErrInvalidAccessToken
+	}
+
+	if err := s.denylist.Deny(ctx, claims.TokenID, time.Until(claims.ExpiresAt)+s.jwtManager.Leeway()); err != nil {
+		slog.ErrorContext(ctx, "failed to deny access token", "userID", claims.UserID, "error", err)
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+	stored, err := s.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrTokenNotFound) {
+			return nil
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting refresh token", "error", err)
+		return err
+	}
+	if stored.UserID != claims.UserID {
+		return nil
+	}
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when revoking refresh tokens", "error", err)
+		return err
+	}
+	return nil
+}
+
+// RevokeSessions ends every session of the user: refresh tokens are revoked and access
+// tokens issued so far are denied until they are no longer accepted.
+func (s *Service) RevokeSessions(ctx context.Context, userID string) error {
+	if err := s.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when revoking user refresh tokens", "userID", userID, "error", err)
+		return err
+	}
+	if err := s.denylist.DenyUser(ctx, userID, s.jwtManager.Duration()+s.jwtManager.Leeway()); err != nil {
+		slog.ErrorContext(ctx, "failed to deny user access tokens", "userID", userID, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "user sessions revoked", "event", "auth.sessions_revoked", "userID", userID)
+	return nil
+}
+
+func (s *Service) handleReuse(ctx context.Context, stored *domain.RefreshToken) error {
+	slog.WarnContext(ctx, "refresh token reuse detected, revoking token family",
+		"userID", stored.UserID,
+		"familyID", stored.FamilyID,
+	)
+	if err := s.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when revoking refresh tokens", "error", err)
+		return err
+	}
+	return domain.ErrRefreshTokenReused
+}
+
+func (s *Service) issueTokens(ctx context.Context, user *domain.User, familyID string) (*TokenPair, error) {
+	var claims jwt.CustomClaims
+	if user.Role != "" {
+		claims.Roles = []string{user.Role}
+	}
+	accessToken, err := s.jwtManager.GenerateTokenWithClaims(user.ID, claims)
 	if err != nil {
 		slog.ErrorContext(ctx, "unknown error when generating jwt token", "error", err)
-		return "", err
+		return nil, err
 	}
-	return token, nil
+
+	raw, err := newRefreshToken()
+	if err != nil {
+		slog.ErrorContext(ctx, "unknown error when generating refresh token", "error", err)
+		return nil, err
+	}
+	if err := s.repo.SaveRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(raw),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when saving refresh token", "error", err)
+		return nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, RefreshToken: raw}, nil
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -3,14 +3,23 @@ package auth_test
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/fernandesenzo/shortener/internal/auth"
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/jwt"
+	"github.com/redis/go-redis/v9"
 )
 
+func newTestDenylist(t *testing.T) (*auth.RedisDenylist, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return auth.NewRedisDenylist(redis.NewClient(&redis.Options{Addr: mr.Addr()})), mr
+}
+
 func TestService_Authenticate(t *testing.T) {
 	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
 	realJwtManager := jwt.NewManager("secret-key-test", 1*time.Hour)
@@ -60,6 +69,18 @@ func TestService_Authenticate(t *testing.T) {
 			wantToken:     false,
 			expectedError: domain.ErrInvalidPassword,
 		},
+		{
+			name:     "failure - disabled account",
+			nickname: "enzo",
+			password: "valid_password",
+			mockRepo: &MockRepository{
+				users: []*domain.User{
+					{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash, DisabledAt: &time.Time{}},
+				},
+			},
+			wantToken:     false,
+			expectedError: domain.ErrAccountDisabled,
+		},
 		{
 			name:     "failure - internal error",
 			nickname: "enzo",
@@ -75,18 +96,227 @@ func TestService_Authenticate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			svc := auth.NewService(tt.mockRepo, realJwtManager)
+			denylist, _ := newTestDenylist(t)
+			svc := auth.NewService(tt.mockRepo, realJwtManager, denylist, nil, time.Hour)
 
-			token, err := svc.Authenticate(context.Background(), tt.nickname, tt.password)
+			tokens, err := svc.Authenticate(context.Background(), tt.nickname, tt.password, "127.0.0.1")
 
 			if !errors.Is(err, tt.expectedError) {
 				t.Errorf("error = %q, expectedError %q", err, tt.expectedError)
 			}
 
-			hasToken := token != ""
+			hasToken := tokens != nil && tokens.AccessToken != "" && tokens.RefreshToken != ""
 			if hasToken != tt.wantToken {
 				t.Errorf("got token (not empty) = %v, wantToken %v", hasToken, tt.wantToken)
 			}
 		})
 	}
 }
+
+func TestService_Refresh(t *testing.T) {
+	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	jwtManager := jwt.NewManager("secret-key-test", time.Hour)
+
+	setup := func(t *testing.T) (*auth.Service, *MockRepository, *auth.TokenPair) {
+		repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash, Role: domain.RoleUser}}}
+		denylist, _ := newTestDenylist(t)
+		svc := auth.NewService(repo, jwtManager, denylist, nil, time.Hour)
+		tokens, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+		return svc, repo, tokens
+	}
+
+	t.Run("rotates refresh token", func(t *testing.T) {
+		svc, repo, tokens := setup(t)
+
+		rotated, err := svc.Refresh(context.Background(), tokens.RefreshToken)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if rotated.RefreshToken == tokens.RefreshToken || rotated.AccessToken == "" {
+			t.Error("expected a new token pair")
+		}
+		if repo.activeTokens() != 1 {
+			t.Errorf("expected only the rotated token to be active, got %d", repo.activeTokens())
+		}
+		if repo.refreshTokens[0].FamilyID != repo.refreshTokens[1].FamilyID {
+			t.Error("expected rotated token to stay in the same family")
+		}
+	})
+
+	t.Run("reuse revokes the family", func(t *testing.T) {
+		svc, repo, tokens := setup(t)
+
+		rotated, err := svc.Refresh(context.Background(), tokens.RefreshToken)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		_, err = svc.Refresh(context.Background(), tokens.RefreshToken)
+		if !errors.Is(err, domain.ErrRefreshTokenReused) {
+			t.Fatalf("expected %v, got %v", domain.ErrRefreshTokenReused, err)
+		}
+		if repo.activeTokens() != 0 {
+			t.Errorf("expected every token in the family to be revoked, got %d active", repo.activeTokens())
+		}
+
+		_, err = svc.Refresh(context.Background(), rotated.RefreshToken)
+		if !errors.Is(err, domain.ErrRefreshTokenReused) {
+			t.Errorf("expected rotated token to be unusable, got %v", err)
+		}
+	})
+
+	t.Run("carries the current role", func(t *testing.T) {
+		svc, repo, tokens := setup(t)
+		claims, _ := jwtManager.ValidateToken(tokens.AccessToken)
+		if !slices.Equal(claims.Roles, []string{domain.RoleUser}) {
+			t.Errorf("expected the user role, got %v", claims.Roles)
+		}
+
+		repo.users[0].Role = domain.RoleAdmin
+		rotated, err := svc.Refresh(context.Background(), tokens.RefreshToken)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		claims, _ = jwtManager.ValidateToken(rotated.AccessToken)
+		if !slices.Equal(claims.Roles, []string{domain.RoleAdmin}) {
+			t.Errorf("expected the promoted role after refreshing, got %v", claims.Roles)
+		}
+	})
+
+	t.Run("disabled account", func(t *testing.T) {
+		svc, repo, tokens := setup(t)
+		repo.users[0].DisabledAt = &time.Time{}
+
+		_, err := svc.Refresh(context.Background(), tokens.RefreshToken)
+		if !errors.Is(err, domain.ErrAccountDisabled) {
+			t.Errorf("expected %v, got %v", domain.ErrAccountDisabled, err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		svc, _, _ := setup(t)
+		_, err := svc.Refresh(context.Background(), "does-not-exist")
+		if !errors.Is(err, domain.ErrInvalidRefreshToken) {
+			t.Errorf("expected %v, got %v", domain.ErrInvalidRefreshToken, err)
+		}
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash}}}
+		denylist, _ := newTestDenylist(t)
+		svc := auth.NewService(repo, jwtManager, denylist, nil, -time.Minute)
+		tokens, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1")
+		if err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+
+		_, err = svc.Refresh(context.Background(), tokens.RefreshToken)
+		if !errors.Is(err, domain.ErrInvalidRefreshToken) {
+			t.Errorf("expected %v, got %v", domain.ErrInvalidRefreshToken, err)
+		}
+	})
+}
+
+func newLeewayManager(t *testing.T) *jwt.Manager {
+	keys, err := jwt.NewKeyring(jwt.NewHMACKey("secret-key-test"))
+	if err != nil {
+		t.Fatalf("NewKeyring() error = %v", err)
+	}
+	return jwt.NewKeyringManager(keys, jwt.ManagerConfig{TTL: time.Hour, Leeway: 30 * time.Second})
+}
+
+func TestService_Logout(t *testing.T) {
+	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	jwtManager := newLeewayManager(t)
+
+	repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash}}}
+	denylist, mr := newTestDenylist(t)
+	svc := auth.NewService(repo, jwtManager, denylist, nil, time.Hour)
+
+	tokens, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+
+	if err := svc.Logout(context.Background(), "garbage", ""); !errors.Is(err, domain.ErrInvalidAccessToken) {
+		t.Errorf("expected %v, got %v", domain.ErrInvalidAccessToken, err)
+	}
+
+	if err := svc.Logout(context.Background(), tokens.AccessToken, tokens.RefreshToken); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	claims, _ := jwtManager.ValidateToken(tokens.AccessToken)
+	denied, err := denylist.IsDenied(context.Background(), claims.TokenID)
+	if err != nil || !denied {
+		t.Errorf("expected access token to be denied, got denied=%v err=%v", denied, err)
+	}
+	if ttl := mr.TTL("jwt:deny:" + claims.TokenID); ttl <= time.Hour || ttl > time.Hour+30*time.Second {
+		t.Errorf("expected denylist entry to outlive the token by the leeway, got ttl %v", ttl)
+	}
+
+	if _, err := svc.Refresh(context.Background(), tokens.RefreshToken); err == nil {
+		t.Error("expected refresh token to be revoked after logout")
+	}
+}
+
+func TestService_RevokeSessions(t *testing.T) {
+	validPasswordHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	jwtManager := newLeewayManager(t)
+
+	repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: validPasswordHash}}}
+	denylist, mr := newTestDenylist(t)
+	svc := auth.NewService(repo, jwtManager, denylist, nil, time.Hour)
+
+	for range 2 {
+		if _, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1"); err != nil {
+			t.Fatalf("login failed: %v", err)
+		}
+	}
+
+	if err := svc.RevokeSessions(context.Background(), "123"); err != nil {
+		t.Fatalf("RevokeSessions() error = %v", err)
+	}
+	if active := repo.activeTokens(); active != 0 {
+		t.Errorf("expected every refresh token to be revoked, %d still active", active)
+	}
+	if ttl := mr.TTL("jwt:deny:user:123"); ttl != time.Hour+30*time.Second {
+		t.Errorf("expected user denial to outlive access tokens, got ttl %v", ttl)
+	}
+	denied, err := denylist.IsUserDenied(context.Background(), "123", time.Now().Add(-time.Minute))
+	if err != nil || !denied {
+		t.Errorf("expected access tokens issued before the revocation to be denied, got denied=%v err=%v", denied, err)
+	}
+}
+
+func TestService_Authenticate_Rehash(t *testing.T) {
+	bcryptHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+	repo := &MockRepository{users: []*domain.User{{ID: "123", Nickname: "enzo", PasswordHash: bcryptHash}}}
+	denylist, _ := newTestDenylist(t)
+	svc := auth.NewService(repo, jwt.NewManager("secret-key-test", time.Hour), denylist, nil, time.Hour)
+
+	if _, err := svc.Authenticate(context.Background(), "enzo", "wrong_password", "127.0.0.1"); err == nil {
+		t.Fatal("expected wrong password to fail")
+	}
+	if repo.users[0].PasswordHash != bcryptHash {
+		t.Fatal("expected hash to be kept after a failed login")
+	}
+
+	if _, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1"); err != nil {
+		t.Fatalf("login failed: %v", err)
+	}
+	upgraded := repo.users[0].PasswordHash
+	if !strings.HasPrefix(upgraded, "$argon2id$") {
+		t.Fatalf("expected bcrypt hash to be upgraded to argon2id, got %q", upgraded)
+	}
+
+	if _, err := svc.Authenticate(context.Background(), "enzo", "valid_password", "127.0.0.1"); err != nil {
+		t.Fatalf("login with upgraded hash failed: %v", err)
+	}
+	if repo.users[0].PasswordHash != upgraded {
+		t.Error("expected an up to date hash not to be rehashed")
+	}
+}
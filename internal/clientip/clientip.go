@@ -0,0 +1,98 @@
+// Package clientip determines the address of the client that sent a request.
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// WithIP stores the resolved client address in the context.
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, ip)
+}
+
+// FromRequest returns the address stored by a Resolver earlier in the chain and
+// falls back to the peer address. X-Forwarded-For is never read here because it
+// can be set by anyone.
+func FromRequest(r *http.Request) string {
+	if ip, ok := r.Context().Value(ctxKey{}).(string); ok && ip != "" {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// Resolver reads X-Forwarded-For only when the request comes through a trusted proxy.
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver parses the CIDRs (or single addresses) of the trusted proxies.
+func NewResolver(trustedProxies []string) (*Resolver, error) {
+	res := &Resolver{}
+	for _, raw := range trustedProxies {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(raw)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(raw)
+			if addrErr != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", raw, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		res.trusted = append(res.trusted, prefix.Masked())
+	}
+	return res, nil
+}
+
+// Resolve returns the client address. The X-Forwarded-For chain is walked from the
+// right, skipping trusted proxies, so entries prepended by the client are ignored.
+func (res *Resolver) Resolve(r *http.Request) string {
+	ip := remoteIP(r)
+	if !res.isTrusted(ip) {
+		return ip
+	}
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if _, err := netip.ParseAddr(hop); err != nil {
+			// a malformed hop cannot be attributed, stop at the last trusted one
+			return ip
+		}
+		ip = hop
+		if !res.isTrusted(hop) {
+			return hop
+		}
+	}
+	return ip
+}
+
+func (res *Resolver) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range res.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
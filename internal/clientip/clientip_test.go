@@ -0,0 +1,64 @@
+package clientip_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/clientip"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	res, err := clientip.NewResolver([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded string
+		want      string
+	}{
+		{name: "direct client", remote: "8.8.8.8:1234", want: "8.8.8.8"},
+		{name: "untrusted peer cannot spoof", remote: "8.8.8.8:1234", forwarded: "1.2.3.4", want: "8.8.8.8"},
+		{name: "trusted proxy", remote: "10.0.0.5:1234", forwarded: "1.2.3.4", want: "1.2.3.4"},
+		{name: "spoofed entry left of proxy chain", remote: "10.0.0.5:1234", forwarded: "6.6.6.6, 1.2.3.4, 192.168.1.1", want: "1.2.3.4"},
+		{name: "only proxies", remote: "10.0.0.5:1234", forwarded: "10.0.0.7", want: "10.0.0.7"},
+		{name: "malformed hop", remote: "10.0.0.5:1234", forwarded: "1.2.3.4, garbage", want: "10.0.0.5"},
+		{name: "trusted proxy without header", remote: "10.0.0.5:1234", want: "10.0.0.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remote
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if got := res.Resolve(req); got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolver_Invalid(t *testing.T) {
+	if _, err := clientip.NewResolver([]string{"not-a-cidr"}); err == nil {
+		t.Error("expected error for invalid cidr")
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "8.8.8.8:1234"
+	req.Header.Set("X-Forwarded-For", "1.2.3.4")
+	if got := clientip.FromRequest(req); got != "8.8.8.8" {
+		t.Errorf("expected peer address without resolver, got %q", got)
+	}
+
+	req = req.WithContext(clientip.WithIP(req.Context(), "5.6.7.8"))
+	if got := clientip.FromRequest(req); got != "5.6.7.8" {
+		t.Errorf("expected resolved address, got %q", got)
+	}
+}
@@ -0,0 +1,347 @@
+// Package config loads the application settings.
+//
+// Values are resolved in increasing order of precedence: the defaults in Default, an
+// optional YAML file, a .env file in the working directory and finally the process
+// environment. Every field can be set from the environment using the variable named
+// in its env tag; durations use Go syntax (e.g. "90s", "24h") and lists are comma separated.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// MinJWTSecretLength is the shortest HMAC secret accepted, 256 bits as recommended for HS256.
+const MinJWTSecretLength = 32
+
+type Config struct {
+	Server    Server    `yaml:"server"`
+	Database  Database  `yaml:"database"`
+	Redis     Redis     `yaml:"redis"`
+	Auth      Auth      `yaml:"auth"`
+	Links     Links     `yaml:"links"`
+	RateLimit RateLimit `yaml:"rateLimit"`
+	Analytics Analytics `yaml:"analytics"`
+	Health    Health    `yaml:"health"`
+	Tracing   Tracing   `yaml:"tracing"`
+}
+
+type Server struct {
+	Port string `yaml:"port" env:"PORT"`
+	// BaseURL is the public origin short links are served from. Defaults to http://localhost:<port>.
+	BaseURL         string        `yaml:"baseUrl" env:"BASE_URL"`
+	ReadTimeout     time.Duration `yaml:"readTimeout" env:"SERVER_READ_TIMEOUT"`
+	WriteTimeout    time.Duration `yaml:"writeTimeout" env:"SERVER_WRITE_TIMEOUT"`
+	IdleTimeout     time.Duration `yaml:"idleTimeout" env:"SERVER_IDLE_TIMEOUT"`
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay is how long the server keeps serving after reporting not ready, so
+	// load balancers can take it out of rotation first.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"SHUTDOWN_DELAY"`
+}
+
+type Database struct {
+	URL string `yaml:"url" env:"DATABASE_URL"`
+}
+
+type Redis struct {
+	URL string `yaml:"url" env:"REDIS_URL"`
+}
+
+type Auth struct {
+	// JWTSecret signs access tokens with HS256 unless JWTSigningKeyFile is set, in
+	// which case it only verifies tokens issued before the switch.
+	JWTSecret string `yaml:"jwtSecret" env:"JWT_SECRET_KEY"`
+	// JWTPreviousSecrets keep verifying tokens signed with rotated out HMAC secrets.
+	JWTPreviousSecrets []string `yaml:"jwtPreviousSecrets" env:"JWT_PREVIOUS_SECRET_KEYS"`
+	// JWTSigningKeyFile is a PEM encoded RSA or Ed25519 private key signing access
+	// tokens with RS256 or EdDSA. Its public key is published at /.well-known/jwks.json.
+	JWTSigningKeyFile string `yaml:"jwtSigningKeyFile" env:"JWT_SIGNING_KEY_FILE"`
+	// JWTVerificationKeyFiles are PEM encoded keys tokens are still accepted from,
+	// typically the previous signing key during a rotation.
+	JWTVerificationKeyFiles []string `yaml:"jwtVerificationKeyFiles" env:"JWT_VERIFICATION_KEY_FILES"`
+	// JWTIssuer is the iss claim of access tokens. Defaults to the base url.
+	JWTIssuer string `yaml:"jwtIssuer" env:"JWT_ISSUER"`
+	// JWTAudience is the aud claim of access tokens; tokens naming none of them are
+	// rejected. Defaults to the issuer.
+	JWTAudience []string `yaml:"jwtAudience" env:"JWT_AUDIENCE"`
+	// JWTLeeway tolerates clock skew when checking token timestamps.
+	JWTLeeway       time.Duration `yaml:"jwtLeeway" env:"JWT_LEEWAY"`
+	AccessTokenTTL  time.Duration `yaml:"accessTokenTtl" env:"ACCESS_TOKEN_TTL"`
+	RefreshTokenTTL time.Duration `yaml:"refreshTokenTtl" env:"REFRESH_TOKEN_TTL"`
+	// LoginMaxAttempts failed logins per nickname, or LoginMaxAttemptsPerIP per client
+	// address, lock further attempts out for LoginLockout, doubling with every further
+	// failure up to LoginMaxLockout. Failures are forgotten after LoginFailureWindow.
+	LoginMaxAttempts      int           `yaml:"loginMaxAttempts" env:"LOGIN_MAX_ATTEMPTS"`
+	LoginMaxAttemptsPerIP int           `yaml:"loginMaxAttemptsPerIp" env:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginLockout          time.Duration `yaml:"loginLockout" env:"LOGIN_LOCKOUT"`
+	LoginMaxLockout       time.Duration `yaml:"loginMaxLockout" env:"LOGIN_MAX_LOCKOUT"`
+	LoginFailureWindow    time.Duration `yaml:"loginFailureWindow" env:"LOGIN_FAILURE_WINDOW"`
+}
+
+type Links struct {
+	// TemporaryTTL is how long links created anonymously live.
+	TemporaryTTL time.Duration `yaml:"temporaryTtl" env:"TEMP_LINK_TTL"`
+	// CacheTTL bounds how long permanent links stay cached in redis.
+	CacheTTL     time.Duration `yaml:"cacheTtl" env:"LINK_CACHE_TTL"`
+	DefaultQuota int           `yaml:"defaultQuota" env:"LINK_QUOTA_DEFAULT"`
+	// ShortenerDomains are refused as destinations on top of the built-in list of
+	// known link shorteners.
+	ShortenerDomains []string `yaml:"shortenerDomains" env:"LINK_SHORTENER_DOMAINS"`
+	// BlocklistFile lists domains refused as destinations, one per line. It is
+	// reloaded when it changes, checked every BlocklistReload.
+	BlocklistFile   string        `yaml:"blocklistFile" env:"LINK_BLOCKLIST_FILE"`
+	BlocklistReload time.Duration `yaml:"blocklistReload" env:"LINK_BLOCKLIST_RELOAD"`
+}
+
+// RateLimit holds the per-route policies. Requests and Window apply to every mutating
+// route without a dedicated policy.
+type RateLimit struct {
+	Requests       int           `yaml:"requests" env:"RATE_LIMIT_REQUESTS"`
+	Window         time.Duration `yaml:"window" env:"RATE_LIMIT_WINDOW"`
+	LoginRequests  int           `yaml:"loginRequests" env:"RATE_LIMIT_LOGIN_REQUESTS"`
+	LoginWindow    time.Duration `yaml:"loginWindow" env:"RATE_LIMIT_LOGIN_WINDOW"`
+	SignupRequests int           `yaml:"signupRequests" env:"RATE_LIMIT_SIGNUP_REQUESTS"`
+	SignupWindow   time.Duration `yaml:"signupWindow" env:"RATE_LIMIT_SIGNUP_WINDOW"`
+	// TrustedProxies lists the CIDRs allowed to set X-Forwarded-For. The header is ignored
+	// for every other peer.
+	TrustedProxies []string `yaml:"trustedProxies" env:"TRUSTED_PROXIES"`
+}
+
+type Analytics struct {
+	IPHashSalt string `yaml:"ipHashSalt" env:"IP_HASH_SALT"`
+}
+
+type Health struct {
+	ReadinessTimeout time.Duration `yaml:"readinessTimeout" env:"READINESS_TIMEOUT"`
+}
+
+type Tracing struct {
+	// Exporter is one of none, otlp, stdout or file.
+	Exporter string `yaml:"exporter" env:"TRACE_EXPORTER"`
+	File     string `yaml:"file" env:"TRACE_FILE"`
+}
+
+// Default returns the settings used when nothing overrides them.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Port:            "8080",
+			ReadTimeout:     10 * time.Second,
+			WriteTimeout:    10 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Auth: Auth{
+			JWTLeeway:             30 * time.Second,
+			AccessTokenTTL:        time.Hour,
+			RefreshTokenTTL:       30 * 24 * time.Hour,
+			LoginMaxAttempts:      5,
+			LoginMaxAttemptsPerIP: 50,
+			LoginLockout:          30 * time.Second,
+			LoginMaxLockout:       15 * time.Minute,
+			LoginFailureWindow:    time.Hour,
+		},
+		Links: Links{
+			TemporaryTTL:    24 * time.Hour,
+			CacheTTL:        24 * time.Hour,
+			DefaultQuota:    10,
+			BlocklistReload: time.Minute,
+		},
+		RateLimit: RateLimit{
+			Requests:       10,
+			Window:         time.Hour,
+			LoginRequests:  20,
+			LoginWindow:    10 * time.Minute,
+			SignupRequests: 5,
+			SignupWindow:   time.Hour,
+		},
+		Health: Health{
+			ReadinessTimeout: 2 * time.Second,
+		},
+		Tracing: Tracing{
+			Exporter: "none",
+			File:     "traces.json",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (skipped when
+// path is empty), .env and the environment, and validates the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env: %w", err)
+	}
+	if err := applyEnv(reflect.ValueOf(cfg).Elem(), os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if cfg.Server.BaseURL == "" {
+		cfg.Server.BaseURL = "http://localhost:" + cfg.Server.Port
+	}
+	if cfg.Auth.JWTIssuer == "" {
+		cfg.Auth.JWTIssuer = cfg.Server.BaseURL
+	}
+	if len(cfg.Auth.JWTAudience) == 0 {
+		cfg.Auth.JWTAudience = []string{cfg.Auth.JWTIssuer}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	port, err := strconv.Atoi(c.Server.Port)
+	check(err == nil && port > 0 && port < 65536, "PORT must be a valid tcp port, got %q", c.Server.Port)
+	u, err := url.Parse(c.Server.BaseURL)
+	check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "BASE_URL must be an absolute http(s) url, got %q", c.Server.BaseURL)
+	check(c.Server.ReadTimeout > 0, "SERVER_READ_TIMEOUT must be positive")
+	check(c.Server.WriteTimeout > 0, "SERVER_WRITE_TIMEOUT must be positive")
+	check(c.Server.IdleTimeout > 0, "SERVER_IDLE_TIMEOUT must be positive")
+	check(c.Server.ShutdownTimeout > 0, "SHUTDOWN_TIMEOUT must be positive")
+	check(c.Server.ShutdownDelay >= 0, "SHUTDOWN_DELAY must not be negative")
+
+	check(c.Database.URL != "", "DATABASE_URL must be set")
+	check(c.Redis.URL != "", "REDIS_URL must be set")
+
+	if c.Auth.JWTSigningKeyFile == "" || c.Auth.JWTSecret != "" {
+		check(len(c.Auth.JWTSecret) >= MinJWTSecretLength, "JWT_SECRET_KEY must have at least %d characters", MinJWTSecretLength)
+	}
+	for _, secret := range c.Auth.JWTPreviousSecrets {
+		check(len(secret) >= MinJWTSecretLength, "JWT_PREVIOUS_SECRET_KEYS must have at least %d characters each", MinJWTSecretLength)
+	}
+	check(c.Auth.JWTLeeway >= 0 && c.Auth.JWTLeeway <= 5*time.Minute, "JWT_LEEWAY must be between 0 and 5m")
+	check(c.Auth.AccessTokenTTL > 0, "ACCESS_TOKEN_TTL must be positive")
+	check(c.Auth.RefreshTokenTTL > c.Auth.AccessTokenTTL, "REFRESH_TOKEN_TTL must be longer than ACCESS_TOKEN_TTL")
+	check(c.Auth.LoginMaxAttempts > 0, "LOGIN_MAX_ATTEMPTS must be positive")
+	check(c.Auth.LoginMaxAttemptsPerIP > 0, "LOGIN_MAX_ATTEMPTS_PER_IP must be positive")
+	check(c.Auth.LoginLockout > 0, "LOGIN_LOCKOUT must be positive")
+	check(c.Auth.LoginMaxLockout >= c.Auth.LoginLockout, "LOGIN_MAX_LOCKOUT must not be shorter than LOGIN_LOCKOUT")
+	check(c.Auth.LoginFailureWindow >= c.Auth.LoginMaxLockout, "LOGIN_FAILURE_WINDOW must not be shorter than LOGIN_MAX_LOCKOUT")
+
+	check(c.Links.TemporaryTTL > 0, "TEMP_LINK_TTL must be positive")
+	check(c.Links.CacheTTL > 0, "LINK_CACHE_TTL must be positive")
+	check(c.Links.DefaultQuota >= 0, "LINK_QUOTA_DEFAULT must not be negative")
+	check(c.Links.BlocklistReload > 0, "LINK_BLOCKLIST_RELOAD must be positive")
+
+	check(c.RateLimit.Requests > 0, "RATE_LIMIT_REQUESTS must be positive")
+	check(c.RateLimit.Window > 0, "RATE_LIMIT_WINDOW must be positive")
+	check(c.RateLimit.LoginRequests > 0, "RATE_LIMIT_LOGIN_REQUESTS must be positive")
+	check(c.RateLimit.LoginWindow > 0, "RATE_LIMIT_LOGIN_WINDOW must be positive")
+	check(c.RateLimit.SignupRequests > 0, "RATE_LIMIT_SIGNUP_REQUESTS must be positive")
+	check(c.RateLimit.SignupWindow > 0, "RATE_LIMIT_SIGNUP_WINDOW must be positive")
+	for _, proxy := range c.RateLimit.TrustedProxies {
+		_, prefixErr := netip.ParsePrefix(proxy)
+		_, addrErr := netip.ParseAddr(proxy)
+		check(prefixErr == nil || addrErr == nil, "TRUSTED_PROXIES must contain cidrs or ip addresses, got %q", proxy)
+	}
+
+	check(c.Health.ReadinessTimeout > 0, "READINESS_TIMEOUT must be positive")
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		check(c.Tracing.File != "", "TRACE_FILE must be set when TRACE_EXPORTER is file")
+	default:
+		check(false, "TRACE_EXPORTER must be one of none, otlp, stdout or file, got %q", c.Tracing.Exporter)
+	}
+
+	return errors.Join(errs...)
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// applyEnv overrides every field carrying an env tag whose variable is set and not empty.
+func applyEnv(v reflect.Value, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct && field.Type() != durationType {
+			if err := applyEnv(field, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name := t.Field(i).Tag.Get("env")
+		if name == "" {
+			continue
+		}
+		raw, _ := lookup(name)
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			continue
+		}
+
+		switch {
+		case field.Type() == durationType:
+			d, err := time.ParseDuration(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", name, raw, err)
+			}
+			field.SetInt(int64(d))
+		case field.Kind() == reflect.Int:
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: %w", name, raw, err)
+			}
+			field.SetInt(int64(n))
+		case field.Kind() == reflect.String:
+			field.SetString(raw)
+		case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+			var items []string
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			field.Set(reflect.ValueOf(items))
+		default:
+			return fmt.Errorf("unsupported config field type %s for %s", field.Type(), name)
+		}
+	}
+	return nil
+}
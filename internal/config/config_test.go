@@ -0,0 +1,118 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/config"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func setRequiredEnv(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	t.Setenv("DATABASE_URL", "postgres://localhost/shortener")
+	t.Setenv("REDIS_URL", "redis://localhost:6379/0")
+	t.Setenv("JWT_SECRET_KEY", testSecret)
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	setRequiredEnv(t)
+
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != "8080" || cfg.Server.BaseURL != "http://localhost:8080" {
+		t.Errorf("unexpected server defaults: %+v", cfg.Server)
+	}
+	if cfg.Links.CacheTTL != 24*time.Hour || cfg.RateLimit.Requests != 10 || cfg.RateLimit.Window != time.Hour {
+		t.Errorf("unexpected defaults: %+v %+v", cfg.Links, cfg.RateLimit)
+	}
+	if cfg.Auth.JWTIssuer != "http://localhost:8080" || len(cfg.Auth.JWTAudience) != 1 || cfg.Auth.JWTAudience[0] != cfg.Auth.JWTIssuer {
+		t.Errorf("expected issuer and audience to default to the base url, got %q %q", cfg.Auth.JWTIssuer, cfg.Auth.JWTAudience)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	setRequiredEnv(t)
+
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	yaml := "server:\n  port: \"9000\"\n  shutdownDelay: 3s\nrateLimit:\n  requests: 50\n  window: 1m\n"
+	if err := os.WriteFile(file, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(".env", []byte("RATE_LIMIT_WINDOW=5m\nLINK_QUOTA_DEFAULT=3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LINK_QUOTA_DEFAULT", "7")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 172.16.0.1")
+
+	cfg, err := config.Load(file)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Server.Port != "9000" || cfg.Server.ShutdownDelay != 3*time.Second || cfg.RateLimit.Requests != 50 {
+		t.Errorf("expected values from file, got %+v %+v", cfg.Server, cfg.RateLimit)
+	}
+	if cfg.RateLimit.Window != 5*time.Minute {
+		t.Errorf("expected .env to override the file, got %s", cfg.RateLimit.Window)
+	}
+	if cfg.Links.DefaultQuota != 7 {
+		t.Errorf("expected the environment to override .env, got %d", cfg.Links.DefaultQuota)
+	}
+	if len(cfg.RateLimit.TrustedProxies) != 2 || cfg.RateLimit.TrustedProxies[1] != "172.16.0.1" {
+		t.Errorf("expected comma separated proxies, got %q", cfg.RateLimit.TrustedProxies)
+	}
+	if cfg.Server.BaseURL != "http://localhost:9000" {
+		t.Errorf("expected base url to follow the port, got %q", cfg.Server.BaseURL)
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		file    string
+		wantErr string
+	}{
+		{name: "short jwt secret", env: map[string]string{"JWT_SECRET_KEY": "secret"}, wantErr: "JWT_SECRET_KEY"},
+		{name: "short previous jwt secret", env: map[string]string{"JWT_PREVIOUS_SECRET_KEYS": "old"}, wantErr: "JWT_PREVIOUS_SECRET_KEYS"},
+		{name: "missing jwt keys", env: map[string]string{"JWT_SECRET_KEY": ""}, wantErr: "JWT_SECRET_KEY"},
+		{name: "excessive jwt leeway", env: map[string]string{"JWT_LEEWAY": "1h"}, wantErr: "JWT_LEEWAY"},
+		{name: "missing database", env: map[string]string{"DATABASE_URL": ""}, wantErr: "DATABASE_URL"},
+		{name: "malformed duration", env: map[string]string{"LINK_CACHE_TTL": "forever"}, wantErr: "LINK_CACHE_TTL"},
+		{name: "malformed int", env: map[string]string{"RATE_LIMIT_REQUESTS": "ten"}, wantErr: "RATE_LIMIT_REQUESTS"},
+		{name: "zero rate limit", env: map[string]string{"RATE_LIMIT_REQUESTS": "0"}, wantErr: "RATE_LIMIT_REQUESTS"},
+		{name: "zero blocklist reload", env: map[string]string{"LINK_BLOCKLIST_RELOAD": "0s"}, wantErr: "LINK_BLOCKLIST_RELOAD"},
+		{name: "invalid trusted proxy", env: map[string]string{"TRUSTED_PROXIES": "10.0.0.0/8,proxy.local"}, wantErr: "TRUSTED_PROXIES"},
+		{name: "unknown exporter", env: map[string]string{"TRACE_EXPORTER": "jaeger"}, wantErr: "TRACE_EXPORTER"},
+		{name: "relative base url", env: map[string]string{"BASE_URL": "sho.rt"}, wantErr: "BASE_URL"},
+		{name: "unknown file key", file: "server:\n  prot: \"80\"\n", wantErr: "prot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequiredEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			path := ""
+			if tt.file != "" {
+				path = filepath.Join(t.TempDir(), "config.yaml")
+				if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := config.Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error mentioning %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
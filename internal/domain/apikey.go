@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+const (
+	ScopeLinksRead  = "links:read"
+	ScopeLinksWrite = "links:write"
+	ScopeKeysManage = "keys:manage"
+	// ScopeAccountManage guards password changes and account deletion.
+	ScopeAccountManage = "account:manage"
+)
+
+// APIKeyScopes lists the scopes a key can be granted. keys:manage and account:manage are
+// intentionally left out so a leaked key cannot be used to mint new keys or take over the account.
+var APIKeyScopes = []string{ScopeLinksRead, ScopeLinksWrite}
+
+type APIKey struct {
+	ID         string
+	UserID     string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+type Click struct {
+	Code      string
+	ClickedAt time.Time
+	Referrer  string
+	UserAgent string
+	IPHash    string
+}
+
+type DailyClicks struct {
+	Date   time.Time
+	Clicks int64
+}
+
+type LinkStats struct {
+	Code           string
+	TotalClicks    int64
+	UniqueVisitors int64
+	Daily          []DailyClicks
+}
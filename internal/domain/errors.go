@@ -4,18 +4,58 @@ import "errors"
 
 // link errors
 var ErrLinkNotFound = errors.New("link not found")
+var ErrLinkExpired = errors.New("link expired")
+var ErrInvalidExpiration = errors.New("expiration date must be in the future")
 var ErrInvalidURL = errors.New("invalid URL")
 var ErrURLTooLong = errors.New("URL too long")
+var ErrUnsupportedURLScheme = errors.New("only http and https URLs can be shortened")
+var ErrSelfReferencingURL = errors.New("URL points back to this shortener")
+var ErrShortenerURL = errors.New("URLs of other link shorteners cannot be shortened")
+var ErrPrivateURL = errors.New("URL points to a local or private network address")
+var ErrBlockedDomain = errors.New("URL domain is blocked")
 var ErrLinkCreationFailed = errors.New("link creation failed")
 var ErrUserExceededLinkLimit = errors.New("user already has too many links saved")
 var ErrUserNotAuthenticated = errors.New("user is not authenticated")
 var ErrUserCannotDeleteLink = errors.New("you cannot delete this link. either it does not exist or you're not the owner")
+var ErrUserCannotUpdateLink = errors.New("you cannot update this link. either it does not exist or you're not the owner")
+var ErrUserCannotViewLinkStats = errors.New("you cannot view stats for this link. either it does not exist or you're not the owner")
+var ErrInvalidStatsPeriod = errors.New("stats period must be between 1 and 365 days")
+var ErrInvalidAlias = errors.New("alias must have 3 to 32 letters, digits, '-' or '_'")
+var ErrAliasReserved = errors.New("alias is reserved")
+var ErrAliasAlreadyTaken = errors.New("alias already taken")
+var ErrAliasRequiresAuth = errors.New("custom aliases are only available to authenticated users")
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+var ErrInvalidPagination = errors.New("invalid pagination parameters")
+var ErrLinkDisabled = errors.New("link was disabled for violating the terms of service")
 
 // user errors
 var ErrNicknameAlreadyUsed = errors.New("nickname already exists")
 var ErrPasswordTooLong = errors.New("password too long")
 var ErrPasswordTooShort = errors.New("password too short")
+var ErrPasswordTooCommon = errors.New("password is too common")
+var ErrUserNotFound = errors.New("user not found")
+var ErrInvalidQuota = errors.New("quota must be zero or a positive number")
+var ErrInvalidNickname = errors.New("nickname must have between 1 and 256 characters")
+var ErrPasswordMismatch = errors.New("current password is incorrect")
+var ErrAccountDisabled = errors.New("account is disabled")
+var ErrCannotDisableSelf = errors.New("administrators cannot disable their own account")
 
 // auth errors
 var ErrInvalidPassword = errors.New("invalid password")
 var ErrNicknameNotFound = errors.New("nickname does not exist")
+var ErrInvalidAccessToken = errors.New("invalid access token")
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+var ErrLogoutRequiresAccessToken = errors.New("logout ends access token sessions; delete the api key to revoke it")
+
+// report errors
+var ErrInvalidReportReason = errors.New("report reason must be one of phishing, malware, spam, illegal, other")
+var ErrReportDetailsTooLong = errors.New("report details must have at most 1000 characters")
+var ErrReportNotFound = errors.New("report not found")
+
+// api key errors
+var ErrInvalidAPIKey = errors.New("invalid api key")
+var ErrInvalidAPIKeyName = errors.New("api key name must have between 1 and 64 characters")
+var ErrInvalidAPIKeyScope = errors.New("api key scopes must be a non empty subset of links:read, links:write")
+var ErrAPIKeyNotFound = errors.New("api key not found")
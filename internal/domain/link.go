@@ -5,6 +5,8 @@ import "time"
 type Link interface {
 	GetCode() string
 	GetOriginalURL() string
+	// IsDisabled reports whether a moderator took the link down.
+	IsDisabled() bool
 }
 type PermanentLink struct {
 	ID          string
@@ -12,15 +14,24 @@ type PermanentLink struct {
 	Code        string
 	UserID      string
 	CreatedAt   time.Time
+	ExpiresAt   *time.Time
+	DisabledAt  *time.Time
 }
 
 func (p PermanentLink) GetCode() string        { return p.Code }
 func (p PermanentLink) GetOriginalURL() string { return p.OriginalURL }
+func (p PermanentLink) IsDisabled() bool       { return p.DisabledAt != nil }
+
+func (p PermanentLink) IsExpired(now time.Time) bool {
+	return p.ExpiresAt != nil && !p.ExpiresAt.After(now)
+}
 
 type TemporaryLink struct {
 	OriginalURL string
 	Code        string
+	Disabled    bool
 }
 
 func (t TemporaryLink) GetCode() string        { return t.Code }
 func (t TemporaryLink) GetOriginalURL() string { return t.OriginalURL }
+func (t TemporaryLink) IsDisabled() bool       { return t.Disabled }
@@ -0,0 +1,10 @@
+package domain
+
+type Quota struct {
+	Limit int
+	Used  int
+}
+
+func (q Quota) Remaining() int {
+	return max(q.Limit-q.Used, 0)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+// Reasons a link can be reported for.
+const (
+	ReportReasonPhishing = "phishing"
+	ReportReasonMalware  = "malware"
+	ReportReasonSpam     = "spam"
+	ReportReasonIllegal  = "illegal"
+	ReportReasonOther    = "other"
+)
+
+var ReportReasons = []string{ReportReasonPhishing, ReportReasonMalware, ReportReasonSpam, ReportReasonIllegal, ReportReasonOther}
+
+// States of a report. Reports stay open until a moderator dismisses them or
+// disables the link they are about.
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// Report is an abuse report filed by anyone about a short link.
+type Report struct {
+	ID         string
+	Code       string
+	Reason     string
+	Details    string
+	Status     string
+	CreatedAt  time.Time
+	ResolvedAt *time.Time
+}
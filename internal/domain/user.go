@@ -2,9 +2,22 @@ package domain
 
 import "time"
 
+// Roles a user can hold. Every user has exactly one.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID           string
 	Nickname     string
 	PasswordHash string
+	Role         string
 	CreatedAt    time.Time
+	// DisabledAt is set while an administrator keeps the account from signing in.
+	DisabledAt *time.Time
+}
+
+func (u *User) Disabled() bool {
+	return u.DisabledAt != nil
 }
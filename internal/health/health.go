@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// CheckFunc reports whether a dependency is reachable.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+type Handler struct {
+	checks  []check
+	timeout time.Duration
+	ready   atomic.Bool
+}
+
+// NewHandler returns a handler whose readiness checks each run with the given timeout.
+// It starts as ready.
+func NewHandler(timeout time.Duration) *Handler {
+	h := &Handler{timeout: timeout}
+	h.ready.Store(true)
+	return h
+}
+
+func (h *Handler) AddCheck(name string, fn CheckFunc) {
+	h.checks = append(h.checks, check{name: name, fn: fn})
+}
+
+// SetReady toggles readiness, e.g. to stop receiving traffic before shutting down.
+func (h *Handler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// Live reports that the process is up and able to serve requests.
+func (h *Handler) Live(w http.ResponseWriter, r *http.Request) {
+	h.sendJSON(w, r, http.StatusOK, statusResponse{Status: StatusUp})
+}
+
+// Ready reports whether every dependency is reachable and the server is not shutting down.
+func (h *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	resp := statusResponse{Status: StatusUp, Checks: make(map[string]string, len(h.checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+			defer cancel()
+
+			status := StatusUp
+			if err := c.fn(ctx); err != nil {
+				slog.WarnContext(r.Context(), "readiness check failed", "check", c.name, "error", err)
+				status = StatusDown
+			}
+			mu.Lock()
+			resp.Checks[c.name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	for _, status := range resp.Checks {
+		if status == StatusDown {
+			resp.Status = StatusDown
+		}
+	}
+	if !h.ready.Load() {
+		resp.Status = StatusDown
+		resp.ShuttingDown = true
+	}
+
+	code := http.StatusOK
+	if resp.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	h.sendJSON(w, r, code, resp)
+}
+
+type statusResponse struct {
+	Status       string            `json:"status"`
+	ShuttingDown bool              `json:"shuttingDown,omitempty"`
+	Checks       map[string]string `json:"checks,omitempty"`
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode json response", "error", err)
+	}
+}
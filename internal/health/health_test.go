@@ -0,0 +1,78 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/health"
+)
+
+func TestHandler_Live(t *testing.T) {
+	h := health.NewHandler(time.Second)
+	h.AddCheck("postgres", func(context.Context) error { return errors.New("down") })
+	h.SetReady(false)
+
+	rr := httptest.NewRecorder()
+	h.Live(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected liveness to ignore dependencies, got %d", rr.Code)
+	}
+}
+
+func TestHandler_Ready(t *testing.T) {
+	up := func(context.Context) error { return nil }
+	down := func(context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name       string
+		postgres   health.CheckFunc
+		redis      health.CheckFunc
+		notReady   bool
+		wantStatus int
+		wantChecks map[string]string
+	}{
+		{name: "all up", postgres: up, redis: up, wantStatus: http.StatusOK, wantChecks: map[string]string{"postgres": "up", "redis": "up"}},
+		{name: "redis down", postgres: up, redis: down, wantStatus: http.StatusServiceUnavailable, wantChecks: map[string]string{"postgres": "up", "redis": "down"}},
+		{name: "postgres times out", postgres: slow, redis: up, wantStatus: http.StatusServiceUnavailable, wantChecks: map[string]string{"postgres": "down", "redis": "up"}},
+		{name: "shutting down", postgres: up, redis: up, notReady: true, wantStatus: http.StatusServiceUnavailable, wantChecks: map[string]string{"postgres": "up", "redis": "up"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := health.NewHandler(20 * time.Millisecond)
+			h.AddCheck("postgres", tt.postgres)
+			h.AddCheck("redis", tt.redis)
+			if tt.notReady {
+				h.SetReady(false)
+			}
+
+			rr := httptest.NewRecorder()
+			h.Ready(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var resp struct {
+				Checks map[string]string `json:"checks"`
+			}
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("error reading response json %v", err)
+			}
+			for name, want := range tt.wantChecks {
+				if resp.Checks[name] != want {
+					t.Errorf("expected %s to be %q, got %q", name, want, resp.Checks[name])
+				}
+			}
+		})
+	}
+}
@@ -1,10 +1,57 @@
 package identity
 
-import "context"
+import (
+	"context"
+	"slices"
+	"time"
+)
 
 type contextKey string
 
 const userIDKey contextKey = "userID"
+const scopesKey contextKey = "scopes"
+const principalKey contextKey = "principal"
+
+// Authentication methods a principal can be established with.
+const (
+	MethodToken  = "token"
+	MethodAPIKey = "api_key"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	UserID string
+	// Method is MethodToken or MethodAPIKey.
+	Method string
+	// CredentialID identifies the access token (its jti) or the api key used.
+	CredentialID string
+	Roles        []string
+	// Scopes restrict the principal; nil means it is not restricted.
+	Scopes []string
+	// ExpiresAt is when the credential stops being valid, zero if it does not expire.
+	ExpiresAt time.Time
+}
+
+// HasRole reports whether the principal was granted role.
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+// WithPrincipal stores the authenticated caller. Its user id and scopes are
+// also visible through GetUserID and HasScope.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	ctx = context.WithValue(ctx, principalKey, p)
+	if p.Scopes != nil {
+		ctx = WithScopes(ctx, p.Scopes)
+	}
+	return WithUserID(ctx, p.UserID)
+}
+
+// GetPrincipal returns the authenticated caller, if any.
+func GetPrincipal(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey).(Principal)
+	return p, ok
+}
 
 func GetUserID(ctx context.Context) (string, bool) {
 	ctxValue := ctx.Value(userIDKey)
@@ -15,3 +62,17 @@ func GetUserID(ctx context.Context) (string, bool) {
 func WithUserID(ctx context.Context, userID string) context.Context {
 	return context.WithValue(ctx, userIDKey, userID)
 }
+
+// WithScopes restricts the request to the given scopes. Requests without scopes
+// (e.g. authenticated with a session token) are not restricted.
+func WithScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, scopesKey, scopes)
+}
+
+func HasScope(ctx context.Context, scope string) bool {
+	scopes, ok := ctx.Value(scopesKey).([]string)
+	if !ok {
+		return true
+	}
+	return slices.Contains(scopes, scope)
+}
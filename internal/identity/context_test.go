@@ -52,3 +52,74 @@ func TestGetUserID(t *testing.T) {
 		})
 	}
 }
+
+func TestHasScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		ctx   context.Context
+		scope string
+		want  bool
+	}{
+		{
+			name:  "no scopes means unrestricted",
+			ctx:   identity.WithUserID(context.Background(), "1234"),
+			scope: "links:write",
+			want:  true,
+		},
+		{
+			name:  "scope granted",
+			ctx:   identity.WithScopes(context.Background(), []string{"links:read", "links:write"}),
+			scope: "links:write",
+			want:  true,
+		},
+		{
+			name:  "scope not granted",
+			ctx:   identity.WithScopes(context.Background(), []string{"links:read"}),
+			scope: "links:write",
+			want:  false,
+		},
+		{
+			name:  "empty scopes grant nothing",
+			ctx:   identity.WithScopes(context.Background(), []string{}),
+			scope: "links:read",
+			want:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := identity.HasScope(tt.ctx, tt.scope); got != tt.want {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithPrincipal(t *testing.T) {
+	ctx := identity.WithPrincipal(context.Background(), identity.Principal{
+		UserID:       "1234",
+		Method:       identity.MethodToken,
+		CredentialID: "jti-1",
+		Roles:        []string{"admin"},
+		Scopes:       []string{"links:read"},
+	})
+
+	p, ok := identity.GetPrincipal(ctx)
+	if !ok || p.CredentialID != "jti-1" || !p.HasRole("admin") || p.HasRole("moderator") {
+		t.Fatalf("unexpected principal %+v", p)
+	}
+	if id, _ := identity.GetUserID(ctx); id != "1234" {
+		t.Errorf("expected user id 1234, got %q", id)
+	}
+	if !identity.HasScope(ctx, "links:read") || identity.HasScope(ctx, "links:write") {
+		t.Error("expected the principal scopes to restrict the request")
+	}
+
+	unrestricted := identity.WithPrincipal(context.Background(), identity.Principal{UserID: "1234"})
+	if !identity.HasScope(unrestricted, "links:write") {
+		t.Error("expected a principal without scopes to be unrestricted")
+	}
+	if _, ok := identity.GetPrincipal(identity.WithUserID(context.Background(), "1234")); ok {
+		t.Error("expected no principal when only a user id is set")
+	}
+}
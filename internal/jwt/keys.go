@@ -0,0 +1,293 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSABits is the smallest RSA modulus accepted for RS256 keys.
+const MinRSABits = 2048
+
+var (
+	ErrNoPEMBlock         = errors.New("jwt: no pem block found")
+	ErrUnsupportedKeyType = errors.New("jwt: unsupported key type")
+	ErrWeakKey            = errors.New("jwt: rsa key is shorter than 2048 bits")
+)
+
+// Key is a single signing or verification key identified by the kid header of
+// the tokens it signs. Keys loaded from a public key can only verify.
+type Key struct {
+	ID      string
+	method  jwt.SigningMethod
+	private any
+	public  any
+}
+
+// NewHMACKey returns an HS256 key. Its id is derived from the secret so every
+// instance sharing the secret agrees on it without extra configuration.
+func NewHMACKey(secret string) *Key {
+	sum := sha256.Sum256([]byte(secret))
+	return &Key{
+		ID:      "hs256-" + hex.EncodeToString(sum[:8]),
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+}
+
+// ParseKeyPEM reads an RSA or Ed25519 key from the first PEM block of data.
+// Private keys sign with RS256 or EdDSA, public keys only verify. The key id
+// is the RFC 7638 thumbprint of the public key.
+func ParseKeyPEM(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrNoPEMBlock
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%w: pem block %q", ErrUnsupportedKeyType, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("jwt: parsing %s: %w", block.Type, err)
+	}
+
+	key := &Key{}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnsupportedKeyType, parsed)
+	}
+	if pub, ok := key.public.(*rsa.PublicKey); ok && pub.N.BitLen() < MinRSABits {
+		return nil, ErrWeakKey
+	}
+
+	jwk, _ := key.JWK()
+	key.ID = jwk.thumbprint()
+	return key, nil
+}
+
+// LoadKeyFile reads a PEM encoded key from path, see ParseKeyPEM.
+func LoadKeyFile(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseKeyPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// Algorithm returns the JWS algorithm of the key.
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+// CanSign reports whether the key holds private material.
+func (k *Key) CanSign() bool {
+	return k.private != nil
+}
+
+// JWK is the public half of an asymmetric key as published in a JWK set.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWK returns the public key in JWK form. Symmetric keys have no public half
+// and report false.
+func (k *Key) JWK() (JWK, bool) {
+	b64 := base64.RawURLEncoding.EncodeToString
+	switch pub := k.public.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			KeyType:   "RSA",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.method.Alg(),
+			N:         b64(pub.N.Bytes()),
+			E:         b64(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case ed25519.PublicKey:
+		return JWK{
+			KeyType:   "OKP",
+			KeyID:     k.ID,
+			Use:       "sig",
+			Algorithm: k.method.Alg(),
+			Curve:     "Ed25519",
+			X:         b64(pub),
+		}, true
+	default:
+		return JWK{}, false
+	}
+}
+
+// thumbprint computes the RFC 7638 thumbprint from the required members only,
+// in lexicographic order.
+func (j JWK) thumbprint() string {
+	var members any
+	if j.KeyType == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{j.E, j.KeyType, j.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{j.Curve, j.KeyType, j.X}
+	}
+	raw, _ := json.Marshal(members)
+	sum := sha256.Sum256(raw)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// Keyring holds the key new tokens are signed with and every key tokens are
+// still accepted from. Rotating means promoting a new signing key while the
+// previous one stays in the ring until the tokens it signed have expired.
+type Keyring struct {
+	signing *Key
+	keys    map[string]*Key
+	order   []*Key
+}
+
+// NewKeyring returns a keyring signing with signing and also verifying with
+// every key in verification. Keys repeating an id already in the ring are ignored.
+func NewKeyring(signing *Key, verification ...*Key) (*Keyring, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, errors.New("jwt: signing key must hold a private key")
+	}
+	ring := &Keyring{signing: signing, keys: make(map[string]*Key)}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, ok := ring.keys[key.ID]; ok {
+			continue
+		}
+		ring.keys[key.ID] = key
+		ring.order = append(ring.order, key)
+	}
+	return ring, nil
+}
+
+// lookup returns the key a token claims to be signed with. Tokens issued
+// before key ids were introduced carry no kid and are checked against every
+// HMAC key in the ring.
+func (r *Keyring) lookup(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		var set jwt.VerificationKeySet
+		for _, key := range r.order {
+			if key.method == jwt.SigningMethodHS256 && token.Method.Alg() == key.method.Alg() {
+				set.Keys = append(set.Keys, key.public)
+			}
+		}
+		if len(set.Keys) == 0 {
+			return nil, jwt.ErrTokenUnverifiable
+		}
+		return set, nil
+	}
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, jwt.ErrTokenUnverifiable
+	}
+	// the algorithm is pinned by the key, never taken from the token
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, jwt.ErrTokenSignatureInvalid
+	}
+	return key.public, nil
+}
+
+// JWKSet is the document served at /.well-known/jwks.json.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys in the ring. HMAC secrets are never published,
+// so a ring of only HMAC keys yields an empty set.
+func (r *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.order {
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// KeyringConfig lists where the keys of a keyring come from.
+type KeyringConfig struct {
+	// Secret is the current HMAC secret. It signs tokens when SigningKeyFile is
+	// empty and only verifies otherwise.
+	Secret          string
+	PreviousSecrets []string
+	SigningKeyFile  string
+	// VerificationKeyFiles are PEM keys accepted but never used to sign.
+	VerificationKeyFiles []string
+}
+
+// LoadKeyring builds a keyring from cfg, reading every key file.
+func LoadKeyring(cfg KeyringConfig) (*Keyring, error) {
+	var keys []*Key
+	if cfg.SigningKeyFile != "" {
+		key, err := LoadKeyFile(cfg.SigningKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if cfg.Secret != "" {
+		keys = append(keys, NewHMACKey(cfg.Secret))
+	}
+	for _, secret := range cfg.PreviousSecrets {
+		keys = append(keys, NewHMACKey(secret))
+	}
+	for _, path := range cfg.VerificationKeyFiles {
+		key, err := LoadKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: no signing key configured")
+	}
+	return NewKeyring(keys[0], keys[1:]...)
+}
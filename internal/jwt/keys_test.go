@@ -0,0 +1,222 @@
+package jwt_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	currentSecret  = "current-secret-with-at-least-32-chars"
+	previousSecret = "previous-secret-with-at-least-32-chars"
+)
+
+func writePEM(t *testing.T, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "key.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func rsaKeyFile(t *testing.T, bits int) (string, *rsa.PrivateKey) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return writePEM(t, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key)), key
+}
+
+func ed25519KeyFiles(t *testing.T) (private, public string) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+	return writePEM(t, "PRIVATE KEY", privDER), writePEM(t, "PUBLIC KEY", pubDER)
+}
+
+func newManager(t *testing.T, cfg jwt.KeyringConfig) *jwt.Manager {
+	t.Helper()
+	keys, err := jwt.LoadKeyring(cfg)
+	if err != nil {
+		t.Fatalf("LoadKeyring() error = %v", err)
+	}
+	return jwt.NewKeyringManager(keys, jwt.ManagerConfig{TTL: time.Hour})
+}
+
+func TestKeyring_Rotation(t *testing.T) {
+	rsaFile, _ := rsaKeyFile(t, 2048)
+	edPrivate, edPublic := ed25519KeyFiles(t)
+
+	old := newManager(t, jwt.KeyringConfig{Secret: previousSecret})
+	oldToken, _ := old.GenerateToken("user-1")
+
+	legacy := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"sub": "user-1",
+		"jti": "legacy",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	legacyToken, _ := legacy.SignedString([]byte(currentSecret))
+
+	tests := []struct {
+		name    string
+		cfg     jwt.KeyringConfig
+		wantAlg string
+	}{
+		{name: "hmac secret", cfg: jwt.KeyringConfig{Secret: currentSecret, PreviousSecrets: []string{previousSecret}}, wantAlg: "HS256"},
+		{name: "rsa signing key", cfg: jwt.KeyringConfig{Secret: currentSecret, PreviousSecrets: []string{previousSecret}, SigningKeyFile: rsaFile}, wantAlg: "RS256"},
+		{name: "ed25519 signing key", cfg: jwt.KeyringConfig{Secret: currentSecret, PreviousSecrets: []string{previousSecret}, SigningKeyFile: edPrivate}, wantAlg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newManager(t, tt.cfg)
+
+			token, err := manager.GenerateToken("user-2")
+			if err != nil {
+				t.Fatalf("GenerateToken() error = %v", err)
+			}
+			parsed, _, err := gojwt.NewParser().ParseUnverified(token, gojwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Method.Alg() != tt.wantAlg {
+				t.Errorf("expected %s, got %s", tt.wantAlg, parsed.Method.Alg())
+			}
+			if kid, _ := parsed.Header["kid"].(string); kid == "" {
+				t.Error("expected a kid header")
+			}
+
+			for name, tok := range map[string]string{"new": token, "rotated out secret": oldToken, "without kid": legacyToken} {
+				if _, err := manager.ValidateToken(tok); err != nil {
+					t.Errorf("expected %s token to validate, got %v", name, err)
+				}
+			}
+		})
+	}
+
+	t.Run("verification only key", func(t *testing.T) {
+		signer := newManager(t, jwt.KeyringConfig{SigningKeyFile: edPrivate})
+		token, _ := signer.GenerateToken("user-3")
+
+		verifier := newManager(t, jwt.KeyringConfig{Secret: currentSecret, VerificationKeyFiles: []string{edPublic}})
+		claims, err := verifier.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.UserID != "user-3" {
+			t.Errorf("expected user-3, got %q", claims.UserID)
+		}
+
+		if _, err := newManager(t, jwt.KeyringConfig{Secret: currentSecret}).ValidateToken(token); err == nil {
+			t.Error("expected a token from an unknown key to be rejected")
+		}
+	})
+}
+
+func TestKeyring_RejectsAlgorithmConfusion(t *testing.T) {
+	rsaFile, private := rsaKeyFile(t, 2048)
+	manager := newManager(t, jwt.KeyringConfig{SigningKeyFile: rsaFile})
+	kid := manager.Keys().JWKS().Keys[0].KeyID
+
+	// an HMAC token keyed with the published public key must not pass as RS256
+	pubDER := x509.MarshalPKCS1PublicKey(&private.PublicKey)
+	forged := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{
+		"sub": "attacker",
+		"jti": "forged",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	forged.Header["kid"] = kid
+	forgedToken, _ := forged.SignedString(pubDER)
+
+	if _, err := manager.ValidateToken(forgedToken); err == nil {
+		t.Error("expected an HS256 token carrying an RS256 kid to be rejected")
+	}
+}
+
+func TestLoadKeyring_Invalid(t *testing.T) {
+	weak, _ := rsaKeyFile(t, 1024)
+	_, edPublic := ed25519KeyFiles(t)
+	garbage := filepath.Join(t.TempDir(), "garbage.pem")
+	_ = os.WriteFile(garbage, []byte("not a key"), 0o600)
+
+	tests := []struct {
+		name    string
+		cfg     jwt.KeyringConfig
+		wantErr error
+	}{
+		{name: "no keys", cfg: jwt.KeyringConfig{}},
+		{name: "missing file", cfg: jwt.KeyringConfig{SigningKeyFile: filepath.Join(t.TempDir(), "missing.pem")}, wantErr: os.ErrNotExist},
+		{name: "not pem", cfg: jwt.KeyringConfig{SigningKeyFile: garbage}, wantErr: jwt.ErrNoPEMBlock},
+		{name: "weak rsa key", cfg: jwt.KeyringConfig{SigningKeyFile: weak}, wantErr: jwt.ErrWeakKey},
+		{name: "public signing key", cfg: jwt.KeyringConfig{SigningKeyFile: edPublic}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := jwt.LoadKeyring(tt.cfg)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestManager_ServeJWKS(t *testing.T) {
+	rsaFile, _ := rsaKeyFile(t, 2048)
+	_, edPublic := ed25519KeyFiles(t)
+	manager := newManager(t, jwt.KeyringConfig{
+		Secret:               currentSecret,
+		SigningKeyFile:       rsaFile,
+		VerificationKeyFiles: []string{edPublic},
+	})
+
+	rec := httptest.NewRecorder()
+	manager.ServeJWKS(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var set jwt.JWKSet
+	if err := json.NewDecoder(rec.Body).Decode(&set); err != nil {
+		t.Fatalf("failed to decode jwk set: %v", err)
+	}
+	if len(set.Keys) != 2 {
+		t.Fatalf("expected the rsa and ed25519 keys without the hmac secret, got %+v", set.Keys)
+	}
+	if set.Keys[0].KeyType != "RSA" || set.Keys[0].N == "" || set.Keys[0].E != "AQAB" {
+		t.Errorf("unexpected rsa key %+v", set.Keys[0])
+	}
+	if set.Keys[1].KeyType != "OKP" || set.Keys[1].Curve != "Ed25519" || set.Keys[1].X == "" {
+		t.Errorf("unexpected ed25519 key %+v", set.Keys[1])
+	}
+
+	token, _ := manager.GenerateToken("user-1")
+	parsed, _, _ := gojwt.NewParser().ParseUnverified(token, gojwt.MapClaims{})
+	if parsed.Header["kid"] != set.Keys[0].KeyID {
+		t.Errorf("expected tokens to reference the published key %q, got %v", set.Keys[0].KeyID, parsed.Header["kid"])
+	}
+
+	rec = httptest.NewRecorder()
+	jwt.NewManager(currentSecret, time.Hour).ServeJWKS(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if body := rec.Body.String(); body != "{\"keys\":[]}\n" {
+		t.Errorf("expected an empty set for hmac only keyrings, got %s", body)
+	}
+}
@@ -1,49 +1,183 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Manager struct {
-	secretKey string
-	duration  time.Duration
+	keys   *Keyring
+	config ManagerConfig
 }
 
+// ManagerConfig sets the lifetime and registered claims of the issued tokens.
+type ManagerConfig struct {
+	// TTL is how long access tokens are valid.
+	TTL time.Duration
+	// Issuer is written to iss and required on validation when not empty.
+	Issuer string
+	// Audience is written to aud; tokens must name at least one of them when not empty.
+	Audience []string
+	// Leeway tolerates clock skew between services checking exp, nbf and iat.
+	Leeway time.Duration
+}
+
+// Claims are the validated claims of an access token.
+type Claims struct {
+	UserID    string
+	TokenID   string
+	Issuer    string
+	Audience  []string
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiresAt time.Time
+	// Roles granted to the user when the token was issued.
+	Roles []string
+	// Scopes restrict the token; nil means it is not restricted.
+	Scopes []string
+}
+
+// CustomClaims are the application specific claims of an access token.
+type CustomClaims struct {
+	Roles  []string
+	Scopes []string
+}
+
+// tokenClaims is the JSON payload of an access token. Scopes travel as a
+// space separated scope claim, as in RFC 8693.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+	Scope string   `json:"scope,omitempty"`
+}
+
+// NewManager returns a manager signing and verifying with a single HS256 secret,
+// without issuer or audience.
 func NewManager(secretKey string, duration time.Duration) *Manager {
-	return &Manager{secretKey: secretKey, duration: duration}
+	keys, _ := NewKeyring(NewHMACKey(secretKey))
+	return NewKeyringManager(keys, ManagerConfig{TTL: duration})
+}
+
+// NewKeyringManager returns a manager signing with the keyring's signing key
+// and accepting tokens from any key in it.
+func NewKeyringManager(keys *Keyring, config ManagerConfig) *Manager {
+	return &Manager{keys: keys, config: config}
 }
 
+// Keys returns the keyring tokens are signed and verified with.
+func (m *Manager) Keys() *Keyring {
+	return m.keys
+}
+
+func (m *Manager) Duration() time.Duration {
+	return m.config.TTL
+}
+
+// Leeway is how long past exp a token is still accepted.
+func (m *Manager) Leeway() time.Duration {
+	return m.config.Leeway
+}
+
+// GenerateToken issues an access token for userID without custom claims.
 func (m *Manager) GenerateToken(userID string) (string, error) {
-	claims := jwt.MapClaims{
-		"sub": userID,
-		"exp": time.Now().Add(m.duration),
-		"iat": time.Now().Unix(), //numericdate
+	return m.GenerateTokenWithClaims(userID, CustomClaims{})
+}
+
+// GenerateTokenWithClaims issues an access token for userID carrying custom.
+func (m *Manager) GenerateTokenWithClaims(userID string, custom CustomClaims) (string, error) {
+	tokenID, err := newTokenID()
+	if err != nil {
+		return "", err
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString([]byte(m.secretKey))
+	now := time.Now()
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    m.config.Issuer,
+			Subject:   userID,
+			Audience:  m.config.Audience,
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.config.TTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        tokenID,
+		},
+		Roles: custom.Roles,
+		Scope: strings.Join(custom.Scopes, " "),
+	}
+	signing := m.keys.signing
+	token := jwt.NewWithClaims(signing.method, claims)
+	token.Header["kid"] = signing.ID
+	signedToken, err := token.SignedString(signing.private)
 	if err != nil {
 		return "", err
 	}
 	return signedToken, nil
 }
-func (m *Manager) ValidateToken(tokenString string) (string, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return []byte(m.secretKey), nil
-	})
+
+// ValidateToken verifies the signature and registered claims of tokenString.
+// Tokens must carry sub, jti and exp; iss and aud are checked when configured.
+func (m *Manager) ValidateToken(tokenString string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithLeeway(m.config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	}
+	if m.config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.config.Issuer))
+	}
+	if len(m.config.Audience) > 0 {
+		opts = append(opts, jwt.WithAudience(m.config.Audience...))
+	}
+
+	var parsed tokenClaims
+	token, err := jwt.ParseWithClaims(tokenString, &parsed, m.keys.lookup, opts...)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if !token.Valid || parsed.Subject == "" || parsed.ID == "" {
+		return nil, jwt.ErrTokenInvalidClaims
+	}
+
+	claims := &Claims{
+		UserID:    parsed.Subject,
+		TokenID:   parsed.ID,
+		Issuer:    parsed.Issuer,
+		Audience:  parsed.Audience,
+		ExpiresAt: parsed.ExpiresAt.Time,
+		Roles:     parsed.Roles,
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userID, ok := claims["sub"].(string)
-		if !ok {
-			return "", jwt.ErrTokenInvalidClaims
-		}
-		return userID, nil
+	if parsed.IssuedAt != nil {
+		claims.IssuedAt = parsed.IssuedAt.Time
+	}
+	if parsed.NotBefore != nil {
+		claims.NotBefore = parsed.NotBefore.Time
+	}
+	if parsed.Scope != "" {
+		claims.Scopes = strings.Fields(parsed.Scope)
+	}
+	return claims, nil
+}
+
+// ServeJWKS writes the public verification keys as a JWK set, letting other
+// services validate tokens without sharing a secret.
+func (m *Manager) ServeJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(m.keys.JWKS()); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write jwk set", "error", err)
+	}
+}
+
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
 	}
-	return "", jwt.ErrTokenInvalidClaims
+	return hex.EncodeToString(b), nil
 }
@@ -0,0 +1,155 @@
+package jwt_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/jwt"
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+func TestManager(t *testing.T) {
+	manager := jwt.NewManager("test-secret", time.Hour)
+
+	t.Run("round trip", func(t *testing.T) {
+		token, err := manager.GenerateToken("user-1")
+		if err != nil {
+			t.Fatalf("GenerateToken() error = %v", err)
+		}
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.UserID != "user-1" {
+			t.Errorf("expected user-1, got %q", claims.UserID)
+		}
+		if claims.TokenID == "" {
+			t.Error("expected a token id")
+		}
+		if time.Until(claims.ExpiresAt) <= 0 || time.Until(claims.ExpiresAt) > time.Hour {
+			t.Errorf("unexpected expiration %v", claims.ExpiresAt)
+		}
+	})
+
+	t.Run("unique token ids", func(t *testing.T) {
+		a, _ := manager.GenerateToken("user-1")
+		b, _ := manager.GenerateToken("user-1")
+		ca, _ := manager.ValidateToken(a)
+		cb, _ := manager.ValidateToken(b)
+		if ca.TokenID == cb.TokenID {
+			t.Error("expected different token ids")
+		}
+	})
+
+	t.Run("rejects other secret", func(t *testing.T) {
+		token, _ := jwt.NewManager("other-secret", time.Hour).GenerateToken("user-1")
+		if _, err := manager.ValidateToken(token); err == nil {
+			t.Error("expected error validating token signed with another secret")
+		}
+	})
+
+	t.Run("rejects expired token", func(t *testing.T) {
+		token, _ := jwt.NewManager("test-secret", -time.Minute).GenerateToken("user-1")
+		if _, err := manager.ValidateToken(token); err == nil {
+			t.Error("expected error validating expired token")
+		}
+	})
+}
+
+func TestManager_RegisteredClaims(t *testing.T) {
+	keys, _ := jwt.NewKeyring(jwt.NewHMACKey("test-secret"))
+	config := jwt.ManagerConfig{
+		TTL:      time.Hour,
+		Issuer:   "https://sho.rt",
+		Audience: []string{"https://sho.rt", "https://stats.sho.rt"},
+		Leeway:   time.Minute,
+	}
+	manager := jwt.NewKeyringManager(keys, config)
+
+	t.Run("custom claims round trip", func(t *testing.T) {
+		token, err := manager.GenerateTokenWithClaims("user-1", jwt.CustomClaims{
+			Roles:  []string{"admin"},
+			Scopes: []string{"links:read", "links:write"},
+		})
+		if err != nil {
+			t.Fatalf("GenerateTokenWithClaims() error = %v", err)
+		}
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.Issuer != config.Issuer || !slices.Equal(claims.Audience, config.Audience) {
+			t.Errorf("unexpected issuer %q or audience %v", claims.Issuer, claims.Audience)
+		}
+		if !slices.Equal(claims.Roles, []string{"admin"}) || !slices.Equal(claims.Scopes, []string{"links:read", "links:write"}) {
+			t.Errorf("unexpected roles %v or scopes %v", claims.Roles, claims.Scopes)
+		}
+		if claims.NotBefore.IsZero() || claims.IssuedAt.IsZero() {
+			t.Error("expected nbf and iat to be set")
+		}
+	})
+
+	t.Run("no custom claims", func(t *testing.T) {
+		token, _ := manager.GenerateToken("user-1")
+		claims, err := manager.ValidateToken(token)
+		if err != nil {
+			t.Fatalf("ValidateToken() error = %v", err)
+		}
+		if claims.Roles != nil || claims.Scopes != nil {
+			t.Errorf("expected an unrestricted token, got roles %v scopes %v", claims.Roles, claims.Scopes)
+		}
+	})
+
+	sign := func(claims gojwt.MapClaims) string {
+		token, _ := gojwt.NewWithClaims(gojwt.SigningMethodHS256, claims).SignedString([]byte("test-secret"))
+		return token
+	}
+	now := time.Now()
+	valid := func(override gojwt.MapClaims) gojwt.MapClaims {
+		claims := gojwt.MapClaims{
+			"iss": "https://sho.rt",
+			"aud": "https://stats.sho.rt",
+			"sub": "user-1",
+			"jti": "token-1",
+			"exp": now.Add(time.Hour).Unix(),
+			"iat": now.Unix(),
+		}
+		for k, v := range override {
+			if v == nil {
+				delete(claims, k)
+			} else {
+				claims[k] = v
+			}
+		}
+		return claims
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{name: "any configured audience", token: sign(valid(nil))},
+		{name: "expired within leeway", token: sign(valid(gojwt.MapClaims{"exp": now.Add(-30 * time.Second).Unix()}))},
+		{name: "issued slightly in the future", token: sign(valid(gojwt.MapClaims{"iat": now.Add(30 * time.Second).Unix()}))},
+		{name: "expired beyond leeway", token: sign(valid(gojwt.MapClaims{"exp": now.Add(-2 * time.Minute).Unix()})), wantErr: true},
+		{name: "not yet valid", token: sign(valid(gojwt.MapClaims{"nbf": now.Add(5 * time.Minute).Unix()})), wantErr: true},
+		{name: "other issuer", token: sign(valid(gojwt.MapClaims{"iss": "https://evil.example"})), wantErr: true},
+		{name: "missing issuer", token: sign(valid(gojwt.MapClaims{"iss": nil})), wantErr: true},
+		{name: "other audience", token: sign(valid(gojwt.MapClaims{"aud": "https://other.example"})), wantErr: true},
+		{name: "missing audience", token: sign(valid(gojwt.MapClaims{"aud": nil})), wantErr: true},
+		{name: "missing subject", token: sign(valid(gojwt.MapClaims{"sub": nil})), wantErr: true},
+		{name: "missing token id", token: sign(valid(gojwt.MapClaims{"jti": nil})), wantErr: true},
+		{name: "missing expiration", token: sign(valid(gojwt.MapClaims{"exp": nil})), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.ValidateToken(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateToken() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package moderation
+
+import "time"
+
+type reportRequest struct {
+	Reason  string `json:"reason"`
+	Details string `json:"details,omitempty"`
+}
+
+type reportResponse struct {
+	ID        string    `json:"id"`
+	Code      string    `json:"code"`
+	Reason    string    `json:"reason"`
+	Details   string    `json:"details,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type listReportsResponse struct {
+	Reports    []reportResponse `json:"reports"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,105 @@
+package moderation
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/pagination"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+type Handler struct {
+	srv *Service
+}
+
+func NewHandler(srv *Service) *Handler {
+	return &Handler{srv: srv}
+}
+
+func (h *Handler) Report(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req reportRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	if err := h.srv.Report(r.Context(), r.PathValue("code"), req.Reason, req.Details); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := ListQuery{Cursor: q.Get("cursor")}
+	limit, err := pagination.ParseLimit(q.Get("limit"))
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	query.Limit = limit
+
+	page, err := h.srv.List(r.Context(), query)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	resp := listReportsResponse{
+		Reports:    make([]reportResponse, 0, len(page.Reports)),
+		NextCursor: page.NextCursor,
+	}
+	for _, report := range page.Reports {
+		resp.Reports = append(resp.Reports, reportResponse{
+			ID:        report.ID,
+			Code:      report.Code,
+			Reason:    report.Reason,
+			Details:   report.Details,
+			CreatedAt: report.CreatedAt,
+		})
+	}
+	h.sendJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) Dismiss(w http.ResponseWriter, r *http.Request) {
+	if err := h.srv.Dismiss(r.Context(), r.PathValue("id")); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) DisableLink(w http.ResponseWriter, r *http.Request) {
+	if err := h.srv.DisableLink(r.Context(), r.PathValue("code")); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) EnableLink(w http.ResponseWriter, r *http.Request) {
+	if err := h.srv.EnableLink(r.Context(), r.PathValue("code")); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) sendJSON(w http.ResponseWriter, r *http.Request, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode json response", "error", err)
+	}
+}
@@ -0,0 +1,101 @@
+package moderation_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/moderation"
+)
+
+func TestHandler_Report(t *testing.T) {
+	tests := []struct {
+		name        string
+		code        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{name: "accepted", code: "abc123", contentType: "application/json", body: `{"reason":"phishing","details":"fake bank"}`, wantStatus: http.StatusAccepted},
+		{name: "invalid reason", code: "abc123", contentType: "application/json", body: `{"reason":"boring"}`, wantStatus: http.StatusUnprocessableEntity, wantCode: "invalid_report_reason"},
+		{name: "unknown link", code: "ghost", contentType: "application/json", body: `{"reason":"spam"}`, wantStatus: http.StatusNotFound, wantCode: "link_not_found"},
+		{name: "unknown field", code: "abc123", contentType: "application/json", body: `{"reason":"spam","url":"x"}`, wantStatus: http.StatusBadRequest, wantCode: "invalid_request_body"},
+		{name: "not json", code: "abc123", contentType: "text/plain", body: `spam`, wantStatus: http.StatusUnsupportedMediaType, wantCode: "unsupported_media_type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := moderation.NewHandler(moderation.NewService(&MockRepository{}, newLinks()))
+
+			req := httptest.NewRequest(http.MethodPost, "/"+tt.code+"/report", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.SetPathValue("code", tt.code)
+			rr := httptest.NewRecorder()
+			handler.Report(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected code %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantCode != "" && !strings.Contains(rr.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("expected problem code %s, got %s", tt.wantCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Queue(t *testing.T) {
+	repo := &MockRepository{}
+	links := newLinks()
+	srv := moderation.NewService(repo, links)
+	handler := moderation.NewHandler(srv)
+	_ = srv.Report(context.Background(), "abc123", domain.ReportReasonPhishing, "fake bank")
+
+	rr := httptest.NewRecorder()
+	handler.List(rr, httptest.NewRequest(http.MethodGet, "/api/admin/reports", nil))
+	var list struct {
+		Reports []struct {
+			ID     string `json:"id"`
+			Code   string `json:"code"`
+			Reason string `json:"reason"`
+		} `json:"reports"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+		t.Fatalf("error reading response json %v", err)
+	}
+	if len(list.Reports) != 1 || list.Reports[0].Code != "abc123" || list.Reports[0].Reason != domain.ReportReasonPhishing {
+		t.Fatalf("unexpected queue %+v", list.Reports)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.List(rr, httptest.NewRequest(http.MethodGet, "/api/admin/reports?limit=abc", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/links/abc123/disable", nil)
+	req.SetPathValue("code", "abc123")
+	rr = httptest.NewRecorder()
+	handler.DisableLink(rr, req)
+	if rr.Code != http.StatusNoContent || !links.disabled["abc123"] {
+		t.Errorf("expected the link to be disabled, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	handler.EnableLink(rr, req)
+	if rr.Code != http.StatusNoContent || links.disabled["abc123"] {
+		t.Errorf("expected the link to be enabled, got %d", rr.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/reports/report-1/dismiss", nil)
+	req.SetPathValue("id", "report-1")
+	rr = httptest.NewRecorder()
+	handler.Dismiss(rr, req)
+	if rr.Code != http.StatusNotFound || !strings.Contains(rr.Body.String(), `"code":"report_not_found"`) {
+		t.Errorf("expected the actioned report to be gone from the queue, got %d %s", rr.Code, rr.Body.String())
+	}
+}
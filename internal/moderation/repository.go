@@ -0,0 +1,25 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+)
+
+type Repository interface {
+	Save(ctx context.Context, report *domain.Report) error
+	// ListOpen pages through the reports still waiting for a moderator, oldest first.
+	ListOpen(ctx context.Context, params ListParams) ([]*domain.Report, error)
+	Dismiss(ctx context.Context, id string) error
+	// ResolveByCode marks every open report about a link as actioned.
+	ResolveByCode(ctx context.Context, code string) (int, error)
+}
+
+type ListParams struct {
+	Limit int
+	After *pagination.Cursor
+}
+
+var ErrRecordNotFound = errors.New("record not found")
@@ -0,0 +1,100 @@
+package moderation_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/moderation"
+)
+
+var ErrMockedError = errors.New("forced db error")
+
+type MockRepository struct {
+	reports     []*domain.Report
+	shouldError bool
+}
+
+func (m *MockRepository) Save(_ context.Context, report *domain.Report) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	report.ID = fmt.Sprintf("report-%d", len(m.reports)+1)
+	report.Status = domain.ReportStatusOpen
+	report.CreatedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(len(m.reports)) * time.Minute)
+	m.reports = append(m.reports, report)
+	return nil
+}
+
+func (m *MockRepository) ListOpen(_ context.Context, params moderation.ListParams) ([]*domain.Report, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	var reports []*domain.Report
+	for _, report := range m.reports {
+		if report.Status != domain.ReportStatusOpen {
+			continue
+		}
+		if params.After != nil && !report.CreatedAt.After(params.After.CreatedAt) {
+			continue
+		}
+		if len(reports) == params.Limit {
+			break
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (m *MockRepository) Dismiss(_ context.Context, id string) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	for _, report := range m.reports {
+		if report.ID == id && report.Status == domain.ReportStatusOpen {
+			report.Status = domain.ReportStatusDismissed
+			return nil
+		}
+	}
+	return moderation.ErrRecordNotFound
+}
+
+func (m *MockRepository) ResolveByCode(_ context.Context, code string) (int, error) {
+	if m.shouldError {
+		return 0, ErrMockedError
+	}
+	resolved := 0
+	for _, report := range m.reports {
+		if report.Code == code && report.Status == domain.ReportStatusOpen {
+			report.Status = domain.ReportStatusActioned
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+// MockLinks stands in for the link service, keyed by code.
+type MockLinks struct {
+	disabled map[string]bool
+}
+
+func (m *MockLinks) Get(_ context.Context, code string) (domain.Link, error) {
+	disabled, ok := m.disabled[code]
+	if !ok {
+		return nil, domain.ErrLinkNotFound
+	}
+	if disabled {
+		return nil, domain.ErrLinkDisabled
+	}
+	return &domain.TemporaryLink{Code: code, OriginalURL: "https://example.com"}, nil
+}
+
+func (m *MockLinks) SetDisabled(_ context.Context, code string, disabled bool) error {
+	if _, ok := m.disabled[code]; !ok {
+		return domain.ErrLinkNotFound
+	}
+	m.disabled[code] = disabled
+	return nil
+}
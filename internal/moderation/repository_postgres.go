@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/lib/pq"
+)
+
+type PostgresRepository struct {
+	db *sql.DB
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{db: db}
+}
+
+func (r *PostgresRepository) Save(ctx context.Context, report *domain.Report) error {
+	query := `
+        INSERT INTO link_reports (code, reason, details)
+        VALUES ($1, $2, $3)
+        RETURNING id, status, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, report.Code, report.Reason, report.Details).Scan(&report.ID, &report.Status, &report.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error saving report: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ListOpen(ctx context.Context, params ListParams) ([]*domain.Report, error) {
+	query := `SELECT id, code, reason, details, status, created_at FROM link_reports WHERE status = 'open'`
+	var args []any
+	if params.After != nil {
+		args = append(args, params.After.CreatedAt, params.After.ID)
+		query += ` AND (created_at, id) > ($1, $2)`
+	}
+	args = append(args, params.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at, id LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := make([]*domain.Report, 0, params.Limit)
+	for rows.Next() {
+		var report domain.Report
+		if err := rows.Scan(&report.ID, &report.Code, &report.Reason, &report.Details, &report.Status, &report.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error scanning report: %w", err)
+		}
+		reports = append(reports, &report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reports: %w", err)
+	}
+	return reports, nil
+}
+
+func (r *PostgresRepository) Dismiss(ctx context.Context, id string) error {
+	query := `UPDATE link_reports SET status = 'dismissed', resolved_at = now() WHERE id = $1 AND status = 'open'`
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("error dismissing report: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) ResolveByCode(ctx context.Context, code string) (int, error) {
+	query := `UPDATE link_reports SET status = 'actioned', resolved_at = now() WHERE code = $1 AND status = 'open'`
+	res, err := r.db.ExecContext(ctx, query, code)
+	if err != nil {
+		return 0, fmt.Errorf("error resolving reports: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(rows), nil
+}
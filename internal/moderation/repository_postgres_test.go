@@ -0,0 +1,65 @@
+package moderation_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/moderation"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+	"github.com/fernandesenzo/shortener/internal/testutil"
+)
+
+func TestPostgresRepository_Reports(t *testing.T) {
+	db, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	repo := moderation.NewPostgresRepository(db)
+	ctx := context.Background()
+
+	var reports []*domain.Report
+	for _, code := range []string{"phish1", "phish1", "spam12"} {
+		report := &domain.Report{Code: code, Reason: domain.ReportReasonPhishing, Details: "fake bank login"}
+		if err := repo.Save(ctx, report); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if report.ID == "" || report.Status != domain.ReportStatusOpen || report.CreatedAt.IsZero() {
+			t.Fatalf("expected id, status and date to be set, got %+v", report)
+		}
+		reports = append(reports, report)
+	}
+
+	page, err := repo.ListOpen(ctx, moderation.ListParams{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListOpen() error = %v", err)
+	}
+	if len(page) != 2 || page[0].ID != reports[0].ID {
+		t.Fatalf("expected the two oldest reports, got %+v", page)
+	}
+	rest, err := repo.ListOpen(ctx, moderation.ListParams{Limit: 2, After: &pagination.Cursor{CreatedAt: page[1].CreatedAt, ID: page[1].ID}})
+	if err != nil {
+		t.Fatalf("ListOpen() error = %v", err)
+	}
+	if len(rest) != 1 || rest[0].ID != reports[2].ID {
+		t.Fatalf("expected the last report, got %+v", rest)
+	}
+
+	if err := repo.Dismiss(ctx, reports[2].ID); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	for _, id := range []string{reports[2].ID, "not-a-uuid"} {
+		if err := repo.Dismiss(ctx, id); !errors.Is(err, moderation.ErrRecordNotFound) {
+			t.Errorf("Dismiss(%s) expected %v, got %v", id, moderation.ErrRecordNotFound, err)
+		}
+	}
+
+	resolved, err := repo.ResolveByCode(ctx, "phish1")
+	if err != nil || resolved != 2 {
+		t.Fatalf("expected 2 reports resolved, got %d, %v", resolved, err)
+	}
+	open, _ := repo.ListOpen(ctx, moderation.ListParams{Limit: 10})
+	if len(open) != 0 {
+		t.Errorf("expected an empty queue, got %+v", open)
+	}
+}
@@ -0,0 +1,125 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+)
+
+const maxDetailsLength = 1000
+
+// LinkModerator is the part of the link service moderation acts on.
+type LinkModerator interface {
+	Get(ctx context.Context, code string) (domain.Link, error)
+	SetDisabled(ctx context.Context, code string, disabled bool) error
+}
+
+type ListQuery struct {
+	Limit  int
+	Cursor string
+}
+
+type ReportPage struct {
+	Reports    []*domain.Report
+	NextCursor string
+}
+
+type Service struct {
+	repo  Repository
+	links LinkModerator
+}
+
+func NewService(repo Repository, links LinkModerator) *Service {
+	return &Service{
+		repo:  repo,
+		links: links,
+	}
+}
+
+// Report files an abuse report about a link. Anyone can report, authenticated or
+// not. Reports about links that are already disabled are accepted but not queued.
+func (s *Service) Report(ctx context.Context, code string, reason string, details string) error {
+	if !slices.Contains(domain.ReportReasons, reason) {
+		return domain.ErrInvalidReportReason
+	}
+	details = strings.TrimSpace(details)
+	if utf8.RuneCountInString(details) > maxDetailsLength {
+		return domain.ErrReportDetailsTooLong
+	}
+
+	if _, err := s.links.Get(ctx, code); err != nil {
+		if errors.Is(err, domain.ErrLinkDisabled) {
+			return nil
+		}
+		return err
+	}
+
+	report := &domain.Report{Code: code, Reason: reason, Details: details}
+	if err := s.repo.Save(ctx, report); err != nil {
+		slog.ErrorContext(ctx, "unknown db error when saving report", "code", code, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "link reported", "event", "report.created", "code", code, "reportID", report.ID, "reason", reason)
+	return nil
+}
+
+// List pages through the open reports, oldest first. It is meant for administrators.
+func (s *Service) List(ctx context.Context, query ListQuery) (*ReportPage, error) {
+	limit, after, err := pagination.Prepare(query.Limit, query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	reports, err := s.repo.ListOpen(ctx, ListParams{Limit: limit + 1, After: after})
+	if err != nil {
+		slog.ErrorContext(ctx, "unknown db error when listing reports", "error", err)
+		return nil, err
+	}
+
+	page := &ReportPage{}
+	page.Reports, page.NextCursor = pagination.Page(reports, limit, func(r *domain.Report) (time.Time, string) {
+		return r.CreatedAt, r.ID
+	})
+	return page, nil
+}
+
+// Dismiss closes an open report without acting on the link.
+func (s *Service) Dismiss(ctx context.Context, id string) error {
+	if err := s.repo.Dismiss(ctx, id); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrReportNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when dismissing report", "reportID", id, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "report dismissed", "event", "report.dismissed", "reportID", id)
+	return nil
+}
+
+// DisableLink takes a link down and closes every open report about it.
+func (s *Service) DisableLink(ctx context.Context, code string) error {
+	if err := s.links.SetDisabled(ctx, code, true); err != nil {
+		return err
+	}
+	resolved, err := s.repo.ResolveByCode(ctx, code)
+	if err != nil {
+		slog.ErrorContext(ctx, "unknown db error when resolving reports", "code", code, "error", err)
+		return err
+	}
+	if resolved > 0 {
+		slog.InfoContext(ctx, "reports actioned", "event", "report.actioned", "code", code, "count", resolved)
+	}
+	return nil
+}
+
+// EnableLink restores a disabled link. Reports closed when it was disabled stay closed.
+func (s *Service) EnableLink(ctx context.Context, code string) error {
+	return s.links.SetDisabled(ctx, code, false)
+}
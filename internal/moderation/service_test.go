@@ -0,0 +1,121 @@
+package moderation_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/moderation"
+)
+
+func newLinks() *MockLinks {
+	return &MockLinks{disabled: map[string]bool{"abc123": false, "off123": true}}
+}
+
+func TestService_Report(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		reason    string
+		details   string
+		wantErr   error
+		wantSaved bool
+	}{
+		{name: "valid report", code: "abc123", reason: domain.ReportReasonPhishing, details: "  fake login page  ", wantSaved: true},
+		{name: "unknown reason", code: "abc123", reason: "ugly", wantErr: domain.ErrInvalidReportReason},
+		{name: "details too long", code: "abc123", reason: domain.ReportReasonOther, details: strings.Repeat("a", 1001), wantErr: domain.ErrReportDetailsTooLong},
+		{name: "unknown link", code: "ghost", reason: domain.ReportReasonSpam, wantErr: domain.ErrLinkNotFound},
+		{name: "already disabled link", code: "off123", reason: domain.ReportReasonSpam},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			srv := moderation.NewService(repo, newLinks())
+
+			err := srv.Report(context.Background(), tt.code, tt.reason, tt.details)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if saved := len(repo.reports) == 1; saved != tt.wantSaved {
+				t.Fatalf("expected saved = %v, got %d reports", tt.wantSaved, len(repo.reports))
+			}
+			if tt.wantSaved && repo.reports[0].Details != "fake login page" {
+				t.Errorf("expected trimmed details, got %q", repo.reports[0].Details)
+			}
+		})
+	}
+}
+
+func TestService_List(t *testing.T) {
+	repo := &MockRepository{}
+	srv := moderation.NewService(repo, newLinks())
+	ctx := context.Background()
+	for range 5 {
+		_ = srv.Report(ctx, "abc123", domain.ReportReasonSpam, "")
+	}
+
+	var ids []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 5 {
+			t.Fatal("pagination did not terminate")
+		}
+		page, err := srv.List(ctx, moderation.ListQuery{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, report := range page.Reports {
+			ids = append(ids, report.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != 5 || ids[0] != "report-1" || ids[4] != "report-5" {
+		t.Errorf("expected every report oldest first, got %v", ids)
+	}
+
+	for _, query := range []moderation.ListQuery{{Limit: -1}, {Limit: 101}, {Cursor: "not-a-cursor"}} {
+		if _, err := srv.List(ctx, query); err == nil {
+			t.Errorf("expected an error for %+v", query)
+		}
+	}
+}
+
+func TestService_Moderate(t *testing.T) {
+	repo := &MockRepository{}
+	links := newLinks()
+	srv := moderation.NewService(repo, links)
+	ctx := context.Background()
+
+	_ = srv.Report(ctx, "abc123", domain.ReportReasonPhishing, "")
+	_ = srv.Report(ctx, "abc123", domain.ReportReasonMalware, "")
+
+	if err := srv.Dismiss(ctx, "report-1"); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	if err := srv.Dismiss(ctx, "report-1"); !errors.Is(err, domain.ErrReportNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrReportNotFound, err)
+	}
+
+	if err := srv.DisableLink(ctx, "abc123"); err != nil {
+		t.Fatalf("DisableLink() error = %v", err)
+	}
+	if !links.disabled["abc123"] {
+		t.Error("expected the link to be disabled")
+	}
+	if repo.reports[0].Status != domain.ReportStatusDismissed || repo.reports[1].Status != domain.ReportStatusActioned {
+		t.Errorf("expected the open report to be actioned, got %s and %s", repo.reports[0].Status, repo.reports[1].Status)
+	}
+
+	if err := srv.EnableLink(ctx, "abc123"); err != nil || links.disabled["abc123"] {
+		t.Errorf("expected the link to be enabled again, got %v", err)
+	}
+	if err := srv.DisableLink(ctx, "ghost"); !errors.Is(err, domain.ErrLinkNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrLinkNotFound, err)
+	}
+}
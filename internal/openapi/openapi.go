@@ -0,0 +1,38 @@
+// Package openapi serves the OpenAPI document describing the HTTP API and a
+// browsable reference rendered from it.
+package openapi
+
+import (
+	_ "embed"
+	"log/slog"
+	"net/http"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+//go:embed docs.html
+var docs []byte
+
+// Spec returns the raw OpenAPI document.
+func Spec() []byte {
+	return spec
+}
+
+// ServeSpec writes the OpenAPI document.
+func ServeSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if _, err := w.Write(spec); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write openapi document", "error", err)
+	}
+}
+
+// ServeDocs writes an HTML page rendering the OpenAPI document with Redoc.
+func ServeDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if _, err := w.Write(docs); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write api docs", "error", err)
+	}
+}
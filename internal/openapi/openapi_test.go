@@ -0,0 +1,51 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/openapi"
+)
+
+func TestSpec_IsValidOpenAPI31(t *testing.T) {
+	var doc struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec(), &doc); err != nil {
+		t.Fatalf("spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.1") {
+		t.Errorf("expected openapi 3.1, got %q", doc.OpenAPI)
+	}
+	if len(doc.Paths) == 0 {
+		t.Error("expected paths to be documented")
+	}
+}
+
+func TestServeSpec(t *testing.T) {
+	rec := httptest.NewRecorder()
+	openapi.ServeSpec(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+}
+
+func TestServeDocs(t *testing.T) {
+	rec := httptest.NewRecorder()
+	openapi.ServeDocs(rec, httptest.NewRequest(http.MethodGet, "/api/docs", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `spec-url="/api/openapi.json"`) {
+		t.Error("expected docs page to load the openapi document")
+	}
+}
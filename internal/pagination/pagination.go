@@ -0,0 +1,85 @@
+// Package pagination implements the keyset pagination shared by list endpoints.
+//
+// Items are ordered by creation date and id. Pages are fetched with one extra
+// item, and when it is there the page is cut back to the limit and the last item
+// kept becomes the cursor of the next page.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// Cursor is the position after which the next page starts.
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+// EncodeCursor returns the opaque cursor of the item created at createdAt with id.
+func EncodeCursor(createdAt time.Time, id string) string {
+	raw, _ := json.Marshal(Cursor{CreatedAt: createdAt.UTC(), ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, domain.ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, domain.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// ParseLimit reads a limit query parameter. An empty value is zero, the default.
+func ParseLimit(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, domain.ErrInvalidPagination
+	}
+	return n, nil
+}
+
+// Prepare checks a requested page. It returns the limit, DefaultLimit when zero,
+// and the decoded cursor, nil when empty.
+func Prepare(limit int, cursor string) (int, *Cursor, error) {
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit < 0 || limit > MaxLimit {
+		return 0, nil, domain.ErrInvalidPagination
+	}
+	if cursor == "" {
+		return limit, nil, nil
+	}
+	after, err := DecodeCursor(cursor)
+	if err != nil {
+		return 0, nil, err
+	}
+	return limit, after, nil
+}
+
+// Page cuts items fetched with limit+1 back to limit. It returns the cursor of
+// the next page, empty on the last one; key gives the creation date and id of an item.
+func Page[T any](items []T, limit int, key func(T) (time.Time, string)) ([]T, string) {
+	if len(items) <= limit {
+		return items, ""
+	}
+	items = items[:limit]
+	return items, EncodeCursor(key(items[limit-1]))
+}
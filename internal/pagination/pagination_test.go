@@ -0,0 +1,79 @@
+package pagination_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, 1, 2, 3, 4, 5, 6, time.FixedZone("BRT", -3*3600))
+
+	c, err := pagination.DecodeCursor(pagination.EncodeCursor(createdAt, "id-1"))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !c.CreatedAt.Equal(createdAt) || c.ID != "id-1" {
+		t.Errorf("unexpected cursor %+v", c)
+	}
+
+	for _, s := range []string{"???", "bm90IGpzb24", "e30"} {
+		if _, err := pagination.DecodeCursor(s); !errors.Is(err, domain.ErrInvalidCursor) {
+			t.Errorf("DecodeCursor(%q) expected %v, got %v", s, domain.ErrInvalidCursor, err)
+		}
+	}
+}
+
+func TestPrepare(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     string
+		cursor    string
+		wantLimit int
+		wantErr   error
+	}{
+		{name: "default", wantLimit: pagination.DefaultLimit},
+		{name: "explicit", limit: "5", wantLimit: 5},
+		{name: "max", limit: "100", wantLimit: 100},
+		{name: "not a number", limit: "ten", wantErr: domain.ErrInvalidPagination},
+		{name: "negative", limit: "-1", wantErr: domain.ErrInvalidPagination},
+		{name: "above max", limit: "101", wantErr: domain.ErrInvalidPagination},
+		{name: "bad cursor", cursor: "???", wantErr: domain.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit, err := pagination.ParseLimit(tt.limit)
+			if err == nil {
+				limit, _, err = pagination.Prepare(limit, tt.cursor)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+			if err == nil && limit != tt.wantLimit {
+				t.Errorf("expected limit %d, got %d", tt.wantLimit, limit)
+			}
+		})
+	}
+}
+
+func TestPage(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := func(i int) (time.Time, string) { return base.Add(time.Duration(i) * time.Hour), string(rune('a' + i)) }
+
+	items, next := pagination.Page([]int{0, 1, 2}, 2, key)
+	if len(items) != 2 || next == "" {
+		t.Fatalf("expected a cut page with a cursor, got %v %q", items, next)
+	}
+	c, _ := pagination.DecodeCursor(next)
+	if c.ID != "b" || !c.CreatedAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("expected the cursor of the last item kept, got %+v", c)
+	}
+
+	if items, next := pagination.Page([]int{0, 1}, 2, key); len(items) != 2 || next != "" {
+		t.Errorf("expected the last page without a cursor, got %v %q", items, next)
+	}
+}
@@ -0,0 +1,58 @@
+// Package password hashes and verifies user passwords.
+//
+// New hashes use argon2id and are stored as PHC strings, e.g.
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>. bcrypt hashes created before the switch
+// are still verified; NeedsRehash reports them so they can be upgraded on login.
+package password
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrMismatch      = errors.New("password does not match")
+	ErrUnknownFormat = errors.New("unknown password hash format")
+)
+
+// Hash hashes pass with argon2id using DefaultParams.
+func Hash(pass string) (string, error) {
+	return hashArgon2id(pass, DefaultParams)
+}
+
+// Compare returns nil when pass matches the argon2id or bcrypt hashedPassword.
+func Compare(hashedPassword, pass string) error {
+	switch {
+	case strings.HasPrefix(hashedPassword, argon2idPrefix):
+		return compareArgon2id(hashedPassword, pass)
+	case isBcrypt(hashedPassword):
+		return compareBcrypt(hashedPassword, pass)
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// NeedsRehash reports whether hashedPassword was produced by an outdated algorithm or
+// with parameters other than DefaultParams.
+func NeedsRehash(hashedPassword string) bool {
+	params, _, _, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return true
+	}
+	return params != DefaultParams
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash string
+)
+
+// CompareDummy spends as long as verifying a real hash, so unknown accounts cannot be
+// told apart by response time.
+func CompareDummy(plainText string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = Hash("dummy password used for timing")
+	})
+	_ = Compare(dummyHash, plainText)
+}
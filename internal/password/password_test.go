@@ -0,0 +1,90 @@
+package password_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/password"
+)
+
+func TestHashAndCompare(t *testing.T) {
+	hash, err := password.Hash("correct horse")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=65536,t=3,p=2$") {
+		t.Errorf("expected a PHC argon2id string, got %q", hash)
+	}
+
+	other, _ := password.Hash("correct horse")
+	if other == hash {
+		t.Error("expected a random salt per hash")
+	}
+
+	if err := password.Compare(hash, "correct horse"); err != nil {
+		t.Errorf("Compare() with the right password error = %v", err)
+	}
+	if err := password.Compare(hash, "wrong horse"); !errors.Is(err, password.ErrMismatch) {
+		t.Errorf("expected %v, got %v", password.ErrMismatch, err)
+	}
+	if password.NeedsRehash(hash) {
+		t.Error("expected a fresh hash not to need a rehash")
+	}
+}
+
+func TestCompare_Legacy(t *testing.T) {
+	// bcrypt hash of "valid_password"
+	bcryptHash := "$2a$12$AhH7jtSk/Y5hkWtkNW3ygePi./4IzRr6F3ocXwLiev5BawuUef9wq"
+
+	if err := password.Compare(bcryptHash, "valid_password"); err != nil {
+		t.Errorf("expected bcrypt hashes to keep verifying, got %v", err)
+	}
+	if err := password.Compare(bcryptHash, "invalid_password"); !errors.Is(err, password.ErrMismatch) {
+		t.Errorf("expected %v, got %v", password.ErrMismatch, err)
+	}
+	if !password.NeedsRehash(bcryptHash) {
+		t.Error("expected bcrypt hashes to need a rehash")
+	}
+}
+
+func TestNeedsRehash_OutdatedParams(t *testing.T) {
+	// argon2id of "password" with m=19456,t=2,p=1
+	weak := "$argon2id$v=19$m=19456,t=2,p=1$c29tZXNhbHRzb21lc2FsdA$5fGyrjXbdUMVJPlbY/TDSAYdo+YqSVR7OSEiVMbjyYw"
+	if !password.NeedsRehash(weak) {
+		t.Error("expected hashes with other parameters to need a rehash")
+	}
+}
+
+func TestCompare_Malformed(t *testing.T) {
+	for _, hash := range []string{"", "plain", "$argon2id$v=19$m=1,t=1$salt$key", "$argon2id$v=16$m=65536,t=3,p=2$c2FsdA$a2V5", "$argon2id$v=19$m=65536,t=3,p=2$!!$a2V5"} {
+		if err := password.Compare(hash, "password"); !errors.Is(err, password.ErrUnknownFormat) {
+			t.Errorf("Compare(%q) expected %v, got %v", hash, password.ErrUnknownFormat, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  error
+	}{
+		{name: "valid", password: "correct horse battery staple"},
+		{name: "too short", password: "abc", wantErr: domain.ErrPasswordTooShort},
+		{name: "longer than the old limit", password: strings.Repeat("x", 64) + "!"},
+		{name: "too long", password: strings.Repeat("x", password.MaxLength+1), wantErr: domain.ErrPasswordTooLong},
+		{name: "multibyte counted by character", password: strings.Repeat("é", 100)},
+		{name: "common", password: "password123", wantErr: domain.ErrPasswordTooCommon},
+		{name: "common in another case", password: "QwErTy123", wantErr: domain.ErrPasswordTooCommon},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := password.Validate(tt.password); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
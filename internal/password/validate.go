@@ -1,13 +1,46 @@
 package password
 
-import "github.com/fernandesenzo/shortener/internal/domain"
+import (
+	_ "embed"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const (
+	MinLength = 6
+	// MaxLength only bounds the cost of hashing; argon2id has no input limit.
+	MaxLength = 128
+)
+
+//go:embed common.txt
+var commonList string
+
+var common = parseCommon(commonList)
+
+func parseCommon(list string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		set[strings.ToLower(line)] = struct{}{}
+	}
+	return set
+}
 
 func Validate(password string) error {
-	if len(password) < 6 {
+	n := utf8.RuneCountInString(password)
+	if n < MinLength {
 		return domain.ErrPasswordTooShort
 	}
-	if len(password) > 20 {
+	if n > MaxLength {
 		return domain.ErrPasswordTooLong
 	}
+	if _, ok := common[strings.ToLower(password)]; ok {
+		return domain.ErrPasswordTooCommon
+	}
 	return nil
 }
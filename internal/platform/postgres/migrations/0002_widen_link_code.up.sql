@@ -0,0 +1 @@
+ALTER TABLE links ALTER COLUMN code TYPE VARCHAR(32);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_links_user_id_created_at ON links (user_id, created_at);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS clicks (
+    id            BIGSERIAL PRIMARY KEY,
+    link_id       UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    clicked_at    TIMESTAMPTZ NOT NULL,
+    referrer      TEXT NOT NULL DEFAULT '',
+    user_agent    TEXT NOT NULL DEFAULT '',
+    ip_hash       VARCHAR(64) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_clicks_link_id_clicked_at ON clicks (link_id, clicked_at);
+
+CREATE TABLE IF NOT EXISTS link_daily_clicks (
+    link_id       UUID NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    day           DATE NOT NULL,
+    clicks        BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (link_id, day)
+);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS link_quota INTEGER CHECK (link_quota >= 0);
@@ -0,0 +1,14 @@
+ALTER TABLE links ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS link_reports (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    code          VARCHAR(32) NOT NULL,
+    reason        VARCHAR(16) NOT NULL CHECK (reason IN ('phishing', 'malware', 'spam', 'illegal', 'other')),
+    details       TEXT NOT NULL DEFAULT '',
+    status        VARCHAR(16) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'dismissed', 'actioned')),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_link_reports_open ON link_reports (created_at, id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_link_reports_code ON link_reports (code);
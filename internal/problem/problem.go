@@ -0,0 +1,158 @@
+// Package problem writes error responses as RFC 7807 problem details.
+//
+// Every response carries a stable, machine-readable "code" member next to the standard
+// fields, so clients can branch on it instead of on the human-readable detail.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const ContentType = "application/problem+json"
+
+// Codes that are not tied to a domain error.
+const (
+	CodeInternal             = "internal_error"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeInvalidBody          = "invalid_request_body"
+	CodeInvalidParameter     = "invalid_parameter"
+	CodeUnauthenticated      = "unauthenticated"
+	CodeInsufficientScope    = "insufficient_scope"
+	CodeInsufficientRole     = "insufficient_role"
+	CodeRateLimited          = "rate_limited"
+)
+
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+type mapping struct {
+	err    error
+	status int
+	code   string
+	// detail replaces the error message when it should not reach the client as is.
+	detail string
+}
+
+var mappings = []mapping{
+	// link errors
+	{err: domain.ErrLinkNotFound, status: http.StatusNotFound, code: "link_not_found"},
+	{err: domain.ErrLinkExpired, status: http.StatusGone, code: "link_expired"},
+	{err: domain.ErrInvalidExpiration, status: http.StatusBadRequest, code: "invalid_expiration"},
+	{err: domain.ErrInvalidURL, status: http.StatusBadRequest, code: "invalid_url"},
+	{err: domain.ErrURLTooLong, status: http.StatusUnprocessableEntity, code: "url_too_long"},
+	{err: domain.ErrUnsupportedURLScheme, status: http.StatusBadRequest, code: "unsupported_url_scheme"},
+	{err: domain.ErrSelfReferencingURL, status: http.StatusUnprocessableEntity, code: "self_referencing_url"},
+	{err: domain.ErrShortenerURL, status: http.StatusUnprocessableEntity, code: "shortener_url"},
+	{err: domain.ErrPrivateURL, status: http.StatusUnprocessableEntity, code: "private_url"},
+	{err: domain.ErrBlockedDomain, status: http.StatusUnprocessableEntity, code: "blocked_domain"},
+	{err: domain.ErrLinkCreationFailed, status: http.StatusInternalServerError, code: "link_creation_failed"},
+	{err: domain.ErrUserExceededLinkLimit, status: http.StatusForbidden, code: "link_quota_exceeded"},
+	{err: domain.ErrUserNotAuthenticated, status: http.StatusUnauthorized, code: CodeUnauthenticated},
+	{err: domain.ErrUserCannotDeleteLink, status: http.StatusForbidden, code: "link_delete_forbidden"},
+	{err: domain.ErrUserCannotUpdateLink, status: http.StatusForbidden, code: "link_update_forbidden"},
+	{err: domain.ErrUserCannotViewLinkStats, status: http.StatusForbidden, code: "link_stats_forbidden"},
+	{err: domain.ErrInvalidStatsPeriod, status: http.StatusBadRequest, code: "invalid_stats_period"},
+	{err: domain.ErrInvalidAlias, status: http.StatusBadRequest, code: "invalid_alias"},
+	{err: domain.ErrAliasReserved, status: http.StatusBadRequest, code: "alias_reserved"},
+	{err: domain.ErrAliasAlreadyTaken, status: http.StatusConflict, code: "alias_taken"},
+	{err: domain.ErrAliasRequiresAuth, status: http.StatusUnauthorized, code: "alias_requires_auth"},
+	{err: domain.ErrInvalidCursor, status: http.StatusBadRequest, code: "invalid_cursor"},
+	{err: domain.ErrInvalidPagination, status: http.StatusBadRequest, code: "invalid_pagination"},
+	{err: domain.ErrLinkDisabled, status: http.StatusUnavailableForLegalReasons, code: "link_disabled"},
+
+	// user errors
+	{err: domain.ErrNicknameAlreadyUsed, status: http.StatusConflict, code: "nickname_taken"},
+	{err: domain.ErrPasswordTooLong, status: http.StatusUnprocessableEntity, code: "password_too_long"},
+	{err: domain.ErrPasswordTooShort, status: http.StatusUnprocessableEntity, code: "password_too_short"},
+	{err: domain.ErrPasswordTooCommon, status: http.StatusUnprocessableEntity, code: "password_too_common"},
+	{err: domain.ErrUserNotFound, status: http.StatusNotFound, code: "user_not_found"},
+	{err: domain.ErrInvalidQuota, status: http.StatusUnprocessableEntity, code: "invalid_quota"},
+	{err: domain.ErrInvalidNickname, status: http.StatusUnprocessableEntity, code: "invalid_nickname"},
+	{err: domain.ErrPasswordMismatch, status: http.StatusForbidden, code: "password_mismatch"},
+	{err: domain.ErrAccountDisabled, status: http.StatusForbidden, code: "account_disabled"},
+	{err: domain.ErrCannotDisableSelf, status: http.StatusUnprocessableEntity, code: "cannot_disable_self"},
+
+	// auth errors. unknown nicknames and wrong passwords share a code so accounts cannot be enumerated
+	{err: domain.ErrInvalidPassword, status: http.StatusUnauthorized, code: "invalid_credentials", detail: "invalid credentials"},
+	{err: domain.ErrNicknameNotFound, status: http.StatusUnauthorized, code: "invalid_credentials", detail: "invalid credentials"},
+	{err: domain.ErrInvalidAccessToken, status: http.StatusUnauthorized, code: "invalid_access_token"},
+	{err: domain.ErrInvalidRefreshToken, status: http.StatusUnauthorized, code: "invalid_refresh_token"},
+	{err: domain.ErrRefreshTokenReused, status: http.StatusUnauthorized, code: "refresh_token_reused"},
+	{err: domain.ErrLogoutRequiresAccessToken, status: http.StatusBadRequest, code: "logout_requires_access_token"},
+	{err: domain.ErrTooManyLoginAttempts, status: http.StatusTooManyRequests, code: "too_many_login_attempts", detail: "too many failed login attempts, retry later"},
+
+	// report errors
+	{err: domain.ErrInvalidReportReason, status: http.StatusUnprocessableEntity, code: "invalid_report_reason"},
+	{err: domain.ErrReportDetailsTooLong, status: http.StatusUnprocessableEntity, code: "report_details_too_long"},
+	{err: domain.ErrReportNotFound, status: http.StatusNotFound, code: "report_not_found"},
+
+	// api key errors
+	{err: domain.ErrInvalidAPIKey, status: http.StatusUnauthorized, code: "invalid_api_key"},
+	{err: domain.ErrInvalidAPIKeyName, status: http.StatusUnprocessableEntity, code: "invalid_api_key_name"},
+	{err: domain.ErrInvalidAPIKeyScope, status: http.StatusUnprocessableEntity, code: "invalid_api_key_scope"},
+	{err: domain.ErrAPIKeyNotFound, status: http.StatusNotFound, code: "api_key_not_found"},
+}
+
+// Write sends a problem with the given status, code and human-readable detail.
+func Write(w http.ResponseWriter, r *http.Request, status int, code string, detail string) {
+	p := Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode problem response", "error", err)
+	}
+}
+
+// Error sends the problem matching a domain error. Anything else is logged and
+// reported as an internal error without leaking its message.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	status, code, detail := Lookup(err)
+	if status >= http.StatusInternalServerError {
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+	}
+	Write(w, r, status, code, detail)
+}
+
+// Lookup returns the status, code and detail Error would send for err.
+func Lookup(err error) (int, string, string) {
+	for _, m := range mappings {
+		if errors.Is(err, m.err) {
+			detail := m.detail
+			if detail == "" {
+				detail = m.err.Error()
+			}
+			return m.status, m.code, detail
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal, "internal server error"
+}
+
+// UnsupportedMediaType reports a request body that is not JSON.
+func UnsupportedMediaType(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "content type must be application/json")
+}
+
+// InvalidBody reports a request body that could not be decoded.
+func InvalidBody(w http.ResponseWriter, r *http.Request) {
+	Write(w, r, http.StatusBadRequest, CodeInvalidBody, "invalid request body")
+}
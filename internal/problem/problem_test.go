@@ -0,0 +1,53 @@
+package problem_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{name: "domain error", err: domain.ErrLinkExpired, wantStatus: http.StatusGone, wantCode: "link_expired", wantDetail: domain.ErrLinkExpired.Error()},
+		{name: "wrapped domain error", err: fmt.Errorf("saving: %w", domain.ErrAliasAlreadyTaken), wantStatus: http.StatusConflict, wantCode: "alias_taken", wantDetail: domain.ErrAliasAlreadyTaken.Error()},
+		{name: "hidden detail", err: domain.ErrNicknameNotFound, wantStatus: http.StatusUnauthorized, wantCode: "invalid_credentials", wantDetail: "invalid credentials"},
+		{name: "unknown error", err: errors.New("pq: connection refused"), wantStatus: http.StatusInternalServerError, wantCode: problem.CodeInternal, wantDetail: "internal server error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			problem.Error(rr, httptest.NewRequest(http.MethodGet, "/api/links", nil), tt.err)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if ct := rr.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Errorf("expected content type %q, got %q", problem.ContentType, ct)
+			}
+
+			var p problem.Details
+			if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+				t.Fatalf("error reading response json %v", err)
+			}
+			if p.Code != tt.wantCode || p.Detail != tt.wantDetail || p.Status != tt.wantStatus {
+				t.Errorf("unexpected problem %+v", p)
+			}
+			if p.Type != "about:blank" || p.Title != http.StatusText(tt.wantStatus) || p.Instance != "/api/links" {
+				t.Errorf("unexpected standard members %+v", p)
+			}
+		})
+	}
+}
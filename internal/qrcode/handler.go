@@ -0,0 +1,134 @@
+package qrcode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/problem"
+)
+
+// CodeInvalidOptions is the problem code sent when the rendering options are invalid.
+const CodeInvalidOptions = "invalid_qr_options"
+
+type LinkResolver interface {
+	Get(ctx context.Context, code string) (domain.Link, error)
+}
+
+type Handler struct {
+	links   LinkResolver
+	baseURL string
+}
+
+// NewHandler returns a handler encoding short links as baseURL + "/" + code.
+func NewHandler(links LinkResolver, baseURL string) *Handler {
+	return &Handler{links: links, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	opts, err := parseOptions(r)
+	if err != nil {
+		problem.Write(w, r, http.StatusBadRequest, CodeInvalidOptions, err.Error())
+		return
+	}
+
+	link, err := h.links.Get(r.Context(), code)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	content := h.baseURL + "/" + link.GetCode()
+	etag := etagFor(content, opts)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Vary", "Accept")
+	if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	img, err := Encode(content, opts)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to generate qr code", "error", err, "code", code)
+		problem.Error(w, r, err)
+		return
+	}
+
+	if opts.Format == FormatSVG {
+		w.Header().Set("Content-Type", "image/svg+xml")
+	} else {
+		w.Header().Set("Content-Type", "image/png")
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(img)))
+	if _, err := w.Write(img); err != nil {
+		slog.ErrorContext(r.Context(), "failed to write qr code", "error", err)
+	}
+}
+
+func parseOptions(r *http.Request) (Options, error) {
+	q := r.URL.Query()
+	opts := Options{
+		Format: q.Get("format"),
+		Size:   DefaultSize,
+		Margin: DefaultMargin,
+		Level:  strings.ToUpper(q.Get("level")),
+	}
+	if opts.Format == "" {
+		opts.Format = formatFromAccept(r.Header.Get("Accept"))
+	}
+	if opts.Level == "" {
+		opts.Level = DefaultLevel
+	}
+	if raw := q.Get("size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, ErrInvalidSize
+		}
+		opts.Size = n
+	}
+	if raw := q.Get("margin"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return opts, ErrInvalidMargin
+		}
+		opts.Margin = n
+	}
+	return opts, opts.Validate()
+}
+
+func formatFromAccept(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		switch mediaType {
+		case "image/svg+xml":
+			return FormatSVG
+		case "image/png":
+			return FormatPNG
+		}
+	}
+	return FormatPNG
+}
+
+func etagFor(content string, opts Options) string {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%s|%d|%d|%s", content, opts.Format, opts.Size, opts.Margin, opts.Level))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
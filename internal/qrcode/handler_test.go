@@ -0,0 +1,99 @@
+package qrcode_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/qrcode"
+)
+
+type stubResolver map[string]domain.Link
+
+func (s stubResolver) Get(_ context.Context, code string) (domain.Link, error) {
+	link, ok := s[code]
+	if !ok {
+		return nil, domain.ErrLinkNotFound
+	}
+	return link, nil
+}
+
+func TestHandler_Get(t *testing.T) {
+	links := stubResolver{
+		"perm01": &domain.PermanentLink{Code: "perm01", OriginalURL: "https://example.com"},
+		"temp01": &domain.TemporaryLink{Code: "temp01", OriginalURL: "https://example.com"},
+	}
+	handler := qrcode.NewHandler(links, "http://sho.rt/")
+
+	tests := []struct {
+		name        string
+		code        string
+		query       string
+		accept      string
+		wantStatus  int
+		wantContent string
+	}{
+		{name: "png by default", code: "perm01", wantStatus: http.StatusOK, wantContent: "image/png"},
+		{name: "svg via accept", code: "temp01", accept: "image/svg+xml", wantStatus: http.StatusOK, wantContent: "image/svg+xml"},
+		{name: "query overrides accept", code: "perm01", query: "?format=png", accept: "image/svg+xml", wantStatus: http.StatusOK, wantContent: "image/png"},
+		{name: "options", code: "perm01", query: "?format=svg&size=512&margin=0&level=h", wantStatus: http.StatusOK, wantContent: "image/svg+xml"},
+		{name: "invalid size", code: "perm01", query: "?size=abc", wantStatus: http.StatusBadRequest},
+		{name: "invalid level", code: "perm01", query: "?level=Z", wantStatus: http.StatusBadRequest},
+		{name: "unknown link", code: "nope01", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/links/"+tt.code+"/qr"+tt.query, nil)
+			req.SetPathValue("code", tt.code)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+			handler.Get(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			if tt.wantContent != "" && rr.Header().Get("Content-Type") != tt.wantContent {
+				t.Errorf("expected content type %q, got %q", tt.wantContent, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestHandler_GetETag(t *testing.T) {
+	links := stubResolver{"perm01": &domain.PermanentLink{Code: "perm01", OriginalURL: "https://example.com"}}
+	handler := qrcode.NewHandler(links, "http://sho.rt")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/links/perm01/qr", nil)
+	req.SetPathValue("code", "perm01")
+	rr := httptest.NewRecorder()
+	handler.Get(rr, req)
+
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("expected etag header")
+	}
+
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.Get(rr, req)
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, rr.Code)
+	}
+	if rr.Body.Len() != 0 {
+		t.Error("expected empty body for not modified")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/links/perm01/qr?size=512", nil)
+	req.SetPathValue("code", "perm01")
+	req.Header.Set("If-None-Match", etag)
+	rr = httptest.NewRecorder()
+	handler.Get(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected different options to miss the etag, got %d", rr.Code)
+	}
+}
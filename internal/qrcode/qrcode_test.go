@@ -0,0 +1,56 @@
+package qrcode_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/qrcode"
+)
+
+func TestEncode(t *testing.T) {
+	t.Run("png has requested size", func(t *testing.T) {
+		img, err := qrcode.Encode("http://localhost:8080/abc123", qrcode.Options{Format: qrcode.FormatPNG, Size: 300, Margin: 2, Level: "H"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		decoded, err := png.Decode(bytes.NewReader(img))
+		if err != nil {
+			t.Fatalf("invalid png: %v", err)
+		}
+		if b := decoded.Bounds(); b.Dx() != 300 || b.Dy() != 300 {
+			t.Errorf("expected 300x300, got %dx%d", b.Dx(), b.Dy())
+		}
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		img, err := qrcode.Encode("http://localhost:8080/abc123", qrcode.Options{Format: qrcode.FormatSVG, Size: 128, Margin: 0, Level: "L"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		svg := string(img)
+		if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `width="128"`) {
+			t.Errorf("unexpected svg output: %.80s", svg)
+		}
+	})
+
+	invalid := []struct {
+		name    string
+		opts    qrcode.Options
+		wantErr error
+	}{
+		{name: "format", opts: qrcode.Options{Format: "gif", Size: 256, Margin: 4, Level: "M"}, wantErr: qrcode.ErrInvalidFormat},
+		{name: "size", opts: qrcode.Options{Format: "png", Size: 10, Margin: 4, Level: "M"}, wantErr: qrcode.ErrInvalidSize},
+		{name: "margin", opts: qrcode.Options{Format: "png", Size: 256, Margin: -1, Level: "M"}, wantErr: qrcode.ErrInvalidMargin},
+		{name: "level", opts: qrcode.Options{Format: "png", Size: 256, Margin: 4, Level: "X"}, wantErr: qrcode.ErrInvalidLevel},
+	}
+	for _, tt := range invalid {
+		t.Run("invalid "+tt.name, func(t *testing.T) {
+			if _, err := qrcode.Encode("x", tt.opts); !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Package ratelimit implements a sliding-window rate limiter stored in redis.
+//
+// Every allowed request is recorded in a sorted set scored by its timestamp. Entries
+// older than the window are dropped before counting, so the limit holds over any
+// window-long interval instead of resetting at fixed boundaries. The check and the
+// insert run in a single Lua script and are therefore atomic across instances.
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Policy is a named limit of Requests per Window.
+type Policy struct {
+	Name     string
+	Requests int
+	Window   time.Duration
+}
+
+// Result describes the state of a key after a call to Allow.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the oldest request in the window expires and a slot frees up.
+	Reset time.Duration
+}
+
+// KEYS[1] the window; ARGV: now (ms), window (ms), limit, member.
+// Returns {allowed, remaining, reset (ms)}.
+var slidingWindow = redis.NewScript(`
+local key = KEYS[1]
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', key, now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', key, window)
+
+local reset = window
+local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+type Limiter struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// Allow records a request for key under policy and reports whether it fits the limit.
+// Rejected requests are not recorded, so clients retrying too early are not penalized further.
+func (l *Limiter) Allow(ctx context.Context, policy Policy, key string) (Result, error) {
+	member, err := randomMember()
+	if err != nil {
+		return Result{}, err
+	}
+	now := l.now().UnixMilli()
+	vals, err := slidingWindow.Run(ctx, l.client,
+		[]string{"rl:" + policy.Name + ":" + key},
+		now, policy.Window.Milliseconds(), policy.Requests, fmt.Sprintf("%d-%s", now, member),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	if len(vals) != 3 {
+		return Result{}, fmt.Errorf("rate limit script returned %d values", len(vals))
+	}
+	return Result{
+		Allowed:   vals[0] == 1,
+		Limit:     policy.Requests,
+		Remaining: int(vals[1]),
+		Reset:     time.Duration(vals[2]) * time.Millisecond,
+	}, nil
+}
+
+func randomMember() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,86 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func setupLimiter(t *testing.T) (*Limiter, *time.Time) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := NewLimiter(client)
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	l, _ := setupLimiter(t)
+	ctx := context.Background()
+	policy := Policy{Name: "test", Requests: 2, Window: time.Minute}
+
+	for i, wantRemaining := range []int{1, 0} {
+		res, err := l.Allow(ctx, policy, "ip:1.2.3.4")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		if !res.Allowed || res.Remaining != wantRemaining || res.Limit != 2 {
+			t.Errorf("request %d: unexpected result %+v", i, res)
+		}
+	}
+
+	res, err := l.Allow(ctx, policy, "ip:1.2.3.4")
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+	if res.Allowed || res.Remaining != 0 {
+		t.Errorf("expected third request to be rejected, got %+v", res)
+	}
+	if res.Reset != time.Minute {
+		t.Errorf("expected reset of a full window, got %s", res.Reset)
+	}
+
+	other, err := l.Allow(ctx, policy, "ip:5.6.7.8")
+	if err != nil || !other.Allowed {
+		t.Errorf("expected keys to be limited independently, got %+v %v", other, err)
+	}
+}
+
+func TestLimiter_SlidingWindow(t *testing.T) {
+	l, now := setupLimiter(t)
+	ctx := context.Background()
+	policy := Policy{Name: "test", Requests: 2, Window: time.Minute}
+
+	allow := func() Result {
+		t.Helper()
+		res, err := l.Allow(ctx, policy, "user:1")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		return res
+	}
+
+	allow()
+	*now = now.Add(40 * time.Second)
+	allow()
+
+	// a fixed window would have reset here; the first request is still within the last minute
+	*now = now.Add(10 * time.Second)
+	if res := allow(); res.Allowed {
+		t.Fatalf("expected rejection inside the sliding window, got %+v", res)
+	} else if res.Reset != 10*time.Second {
+		t.Errorf("expected reset when the oldest request expires, got %s", res.Reset)
+	}
+
+	*now = now.Add(11 * time.Second)
+	if res := allow(); !res.Allowed {
+		t.Errorf("expected a slot to free up once the oldest request left the window, got %+v", res)
+	}
+}
@@ -0,0 +1,42 @@
+package shortener
+
+import (
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const (
+	minAliasLength = 3
+	maxAliasLength = 32
+)
+
+var reservedAliases = map[string]struct{}{
+	"api":     {},
+	"admin":   {},
+	"login":   {},
+	"logout":  {},
+	"users":   {},
+	"links":   {},
+	"static":  {},
+	"docs":    {},
+	"health":  {},
+	"healthz": {},
+	"readyz":  {},
+	"metrics": {},
+}
+
+func validateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return domain.ErrInvalidAlias
+	}
+	for _, c := range alias {
+		if !strings.ContainsRune(validChars, c) && c != '-' && c != '_' {
+			return domain.ErrInvalidAlias
+		}
+	}
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return domain.ErrAliasReserved
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package shortener
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// domainSet matches hostnames against a set of domains and their subdomains.
+type domainSet map[string]struct{}
+
+func newDomainSet(domains ...string) domainSet {
+	set := make(domainSet, len(domains))
+	for _, d := range domains {
+		if d = normalizeHost(d); d != "" {
+			set[d] = struct{}{}
+		}
+	}
+	return set
+}
+
+// contains reports whether host is one of the domains or a subdomain of one.
+func (s domainSet) contains(host string) bool {
+	for {
+		if _, ok := s[host]; ok {
+			return true
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			return false
+		}
+		host = parent
+	}
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+}
+
+// Blocklist is a set of domains read from a file, one per line. Blank lines and
+// lines starting with # are ignored. A domain also blocks all of its subdomains.
+type Blocklist struct {
+	path    string
+	domains atomic.Pointer[domainSet]
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+}
+
+// LoadBlocklist reads the blocklist at path.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	b := &Blocklist{path: path}
+	if err := b.Reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Reload reads the file again. On failure the domains loaded before stay in use.
+func (b *Blocklist) Reload() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	f, err := os.Open(b.path)
+	if err != nil {
+		return fmt.Errorf("opening blocklist: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("reading blocklist: %w", err)
+	}
+
+	var domains []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		domains = append(domains, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading blocklist: %w", err)
+	}
+
+	set := newDomainSet(domains...)
+	b.domains.Store(&set)
+	b.modTime, b.size = info.ModTime(), info.Size()
+	return nil
+}
+
+// Watch reloads the file whenever it changes, checking every interval until ctx is done.
+func (b *Blocklist) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !b.changed() {
+			continue
+		}
+		if err := b.Reload(); err != nil {
+			slog.WarnContext(ctx, "failed to reload blocklist, keeping the previous one", "path", b.path, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "blocklist reloaded", "event", "blocklist.reloaded", "path", b.path, "domains", b.Len())
+	}
+}
+
+func (b *Blocklist) changed() bool {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		// a missing file is reported by Reload
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !info.ModTime().Equal(b.modTime) || info.Size() != b.size
+}
+
+// Blocks reports whether host is a blocked domain or one of its subdomains.
+func (b *Blocklist) Blocks(host string) bool {
+	return (*b.domains.Load()).contains(normalizeHost(host))
+}
+
+// Len returns the number of domains in the list.
+func (b *Blocklist) Len() int {
+	return len(*b.domains.Load())
+}
@@ -0,0 +1,62 @@
+package shortener_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fernandesenzo/shortener/internal/shortener"
+)
+
+func TestBlocklist_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("evil.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blocklist, err := shortener.LoadBlocklist(path)
+	if err != nil {
+		t.Fatalf("LoadBlocklist() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go blocklist.Watch(ctx, 10*time.Millisecond)
+
+	if err := os.WriteFile(path, []byte("evil.example\nworse.example\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return blocklist.Blocks("worse.example") })
+
+	// a file that disappears keeps the last list in use
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !blocklist.Blocks("evil.example") || blocklist.Len() != 2 {
+		t.Errorf("expected the previous list to stay in use, got %d domains", blocklist.Len())
+	}
+
+	if err := os.WriteFile(path, []byte("# emptied\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return blocklist.Len() == 0 })
+}
+
+func TestLoadBlocklist_Missing(t *testing.T) {
+	if _, err := shortener.LoadBlocklist(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -1,6 +1,14 @@
 package shortener
 
+import "time"
+
 type shortenLinkRequest struct {
+	URL       string     `json:"url"`
+	Alias     string     `json:"alias,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+}
+
+type updateLinkRequest struct {
 	URL string `json:"url"`
 }
 
@@ -11,3 +19,16 @@ type shortenLinkResponse struct {
 type deleteLinkResponse struct {
 	Message string `json:"message"`
 }
+
+type linkResponse struct {
+	Code        string     `json:"code"`
+	OriginalURL string     `json:"originalUrl"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+	DisabledAt  *time.Time `json:"disabledAt,omitempty"`
+}
+
+type listLinksResponse struct {
+	Links      []linkResponse `json:"links"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
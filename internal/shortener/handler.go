@@ -1,23 +1,38 @@
 package shortener
 
 import (
+	_ "embed"
 	"encoding/json"
-	"errors"
+	"html/template"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/metrics"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+	"github.com/fernandesenzo/shortener/internal/problem"
 )
 
+//go:embed unavailable.html
+var unavailablePage string
+
+var unavailableTemplate = template.Must(template.New("unavailable").Parse(unavailablePage))
+
+type ClickRecorder interface {
+	Record(code string, r *http.Request)
+}
+
 type Handler struct {
-	srv *Service
+	srv    *Service
+	clicks ClickRecorder
 }
 
-func NewHandler(srv *Service) *Handler {
+func NewHandler(srv *Service, clicks ClickRecorder) *Handler {
 	return &Handler{
-		srv: srv,
+		srv:    srv,
+		clicks: clicks,
 	}
 }
 
@@ -26,21 +41,25 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 
 	link, err := h.srv.Get(r.Context(), code)
 	if err != nil {
-		if errors.Is(err, domain.ErrLinkNotFound) {
-			http.Error(w, "link not found", http.StatusNotFound)
+		status, _, detail := problem.Lookup(err)
+		if (status == http.StatusGone || status == http.StatusUnavailableForLegalReasons) && acceptsHTML(r) {
+			h.writeUnavailable(w, r, status, detail)
 			return
 		}
-		slog.ErrorContext(r.Context(), "failed to get link", "error", err, "code", code)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
+	if h.clicks != nil {
+		h.clicks.Record(link.GetCode(), r)
+	}
+	metrics.IncRedirect()
 	http.Redirect(w, r, link.GetOriginalURL(), http.StatusTemporaryRedirect)
 }
 
 func (h *Handler) Shorten(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
 	if !strings.Contains(contentType, "application/json") {
-		http.Error(w, "unsupported content type", http.StatusUnsupportedMediaType)
+		problem.UnsupportedMediaType(w, r)
 		return
 	}
 
@@ -48,38 +67,23 @@ func (h *Handler) Shorten(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
 	dec.DisallowUnknownFields()
 	if err := dec.Decode(&req); err != nil {
-		http.Error(w, "invalid request", http.StatusBadRequest)
+		problem.InvalidBody(w, r)
 		return
 	}
 
 	userID, ok := identity.GetUserID(r.Context())
 	if !ok {
 		slog.ErrorContext(r.Context(), "failed to retrieve userID from context")
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		problem.Write(w, r, http.StatusInternalServerError, problem.CodeInternal, "internal server error")
+		return
 	}
 
-	link, err := h.srv.Shorten(r.Context(), req.URL, userID)
+	link, err := h.srv.Shorten(r.Context(), req.URL, userID, ShortenOptions{Alias: req.Alias, ExpiresAt: req.ExpiresAt})
 	if err != nil {
-		if errors.Is(err, domain.ErrLinkCreationFailed) {
-			slog.ErrorContext(r.Context(), "failed to create link", "error", err, "url", req.URL)
-			http.Error(w, "internal server error", http.StatusInternalServerError)
-			return
-		}
-		slog.WarnContext(r.Context(), "user sent bad request", "error", err, "url", req.URL)
-		if errors.Is(err, domain.ErrURLTooLong) {
-			http.Error(w, "url too long", http.StatusUnprocessableEntity)
-			return
+		if status, _, _ := problem.Lookup(err); status < http.StatusInternalServerError {
+			slog.WarnContext(r.Context(), "user sent bad request", "error", err, "url", req.URL)
 		}
-		if errors.Is(err, domain.ErrInvalidURL) {
-			http.Error(w, "invalid url", http.StatusBadRequest)
-			return
-		}
-		if errors.Is(err, domain.ErrUserExceededLinkLimit) {
-			http.Error(w, "link limit exceeded", http.StatusForbidden)
-			return
-		}
-		slog.ErrorContext(r.Context(), "unknown error when creating link", "error", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -97,28 +101,125 @@ func (h *Handler) Shorten(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	code := r.PathValue("code")
 
-	err := h.srv.Delete(r.Context(), code)
-	if err != nil {
-		if errors.Is(err, domain.ErrUserCannotDeleteLink) {
-			http.Error(w, domain.ErrUserCannotDeleteLink.Error(), http.StatusForbidden) // 403
-			return
-		}
+	if err := h.srv.Delete(r.Context(), code); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
 
-		if errors.Is(err, domain.ErrUserNotAuthenticated) {
-			http.Error(w, domain.ErrUserNotAuthenticated.Error(), http.StatusUnauthorized)
-			return
-		}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	resp := deleteLinkResponse{Message: "successfully deleted"}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req updateLinkRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
 
-		slog.ErrorContext(r.Context(), "unexpected error deleting link", "error", err)
-		http.Error(w, "internal server error", http.StatusInternalServerError) // 500
+	code := r.PathValue("code")
+	link, err := h.srv.Update(r.Context(), code, req.URL)
+	if err != nil {
+		problem.Error(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	resp := deleteLinkResponse{Message: "successfully deleted"}
+	resp := linkResponse{
+		Code:        link.Code,
+		OriginalURL: link.OriginalURL,
+		CreatedAt:   link.CreatedAt,
+		ExpiresAt:   link.ExpiresAt,
+		DisabledAt:  link.DisabledAt,
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
+	}
+}
+
+func (h *Handler) AdminDelete(w http.ResponseWriter, r *http.Request) {
+	if err := h.srv.DeleteAny(r.Context(), r.PathValue("code")); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := ListQuery{
+		Cursor:      q.Get("cursor"),
+		URLContains: q.Get("q"),
+	}
+	limit, err := pagination.ParseLimit(q.Get("limit"))
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	query.Limit = limit
+	switch q.Get("order") {
+	case "", "desc":
+	case "asc":
+		query.Ascending = true
+	default:
+		problem.Error(w, r, domain.ErrInvalidPagination)
+		return
+	}
+
+	page, err := h.srv.List(r.Context(), query)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	resp := listLinksResponse{
+		Links:      make([]linkResponse, 0, len(page.Links)),
+		NextCursor: page.NextCursor,
+	}
+	for _, link := range page.Links {
+		resp.Links = append(resp.Links, linkResponse{
+			Code:        link.Code,
+			OriginalURL: link.OriginalURL,
+			CreatedAt:   link.CreatedAt,
+			ExpiresAt:   link.ExpiresAt,
+			DisabledAt:  link.DisabledAt,
+		})
+	}
 
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.ErrorContext(r.Context(), "failed to encode response", "error", err)
 	}
 }
+
+// writeUnavailable renders the page browsers following an expired or disabled
+// link land on instead of a problem document.
+func (h *Handler) writeUnavailable(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	data := struct{ Title, Detail string }{http.StatusText(status), detail}
+	if err := unavailableTemplate.Execute(w, data); err != nil {
+		slog.ErrorContext(r.Context(), "failed to render unavailable page", "error", err)
+	}
+}
+
+func acceptsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
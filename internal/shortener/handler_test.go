@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/identity"
@@ -35,7 +36,7 @@ func TestHandlerGet(t *testing.T) {
 			setupLink:      nil,
 			shouldError:    false,
 			expectedStatus: http.StatusNotFound,
-			expectedBody:   "link not found",
+			expectedBody:   `"code":"link_not_found"`,
 		},
 		{
 			name:           "Internal Error",
@@ -43,7 +44,7 @@ func TestHandlerGet(t *testing.T) {
 			setupLink:      nil,
 			shouldError:    true,
 			expectedStatus: http.StatusInternalServerError,
-			expectedBody:   "internal server error",
+			expectedBody:   `"code":"internal_error"`,
 		},
 	}
 
@@ -57,8 +58,8 @@ func TestHandlerGet(t *testing.T) {
 				_ = repo.save(context.Background(), tt.setupLink)
 			}
 
-			service := shortener.NewService(repo)
-			handler := shortener.NewHandler(service)
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+			handler := shortener.NewHandler(service, nil)
 
 			req := httptest.NewRequest(http.MethodGet, "/"+tt.codeParam, nil)
 			req.SetPathValue("code", tt.codeParam)
@@ -77,6 +78,98 @@ func TestHandlerGet(t *testing.T) {
 	}
 }
 
+type recorderSpy struct {
+	codes []string
+}
+
+func (r *recorderSpy) Record(code string, _ *http.Request) {
+	r.codes = append(r.codes, code)
+}
+
+func TestHandlerGet_RecordsClick(t *testing.T) {
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.TemporaryLink{Code: "abcdef", OriginalURL: "https://google.com"})
+	spy := &recorderSpy{}
+	handler := shortener.NewHandler(shortener.NewService(repo, 24*time.Hour, nil), spy)
+
+	for _, code := range []string{"abcdef", "missing"} {
+		req := httptest.NewRequest(http.MethodGet, "/"+code, nil)
+		req.SetPathValue("code", code)
+		handler.Get(httptest.NewRecorder(), req)
+	}
+
+	if len(spy.codes) != 1 || spy.codes[0] != "abcdef" {
+		t.Errorf("expected a single click for abcdef, got %v", spy.codes)
+	}
+}
+
+func TestHandlerGet_Expired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.PermanentLink{Code: "old123", OriginalURL: "https://google.com", UserID: "123", ExpiresAt: &past})
+
+	handler := shortener.NewHandler(shortener.NewService(repo, 24*time.Hour, nil), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/old123", nil)
+	req.SetPathValue("code", "old123")
+	w := httptest.NewRecorder()
+
+	handler.Get(w, req)
+
+	if w.Code != http.StatusGone {
+		t.Errorf("expected status %d, got %d", http.StatusGone, w.Code)
+	}
+}
+
+func TestHandlerGet_Disabled(t *testing.T) {
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.PermanentLink{Code: "bad123", OriginalURL: "https://phishing.example", UserID: "123"})
+	spy := &recorderSpy{}
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+	if err := service.SetDisabled(context.Background(), "bad123", true); err != nil {
+		t.Fatal(err)
+	}
+	handler := shortener.NewHandler(service, spy)
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		wantBody        string
+	}{
+		{name: "api client", accept: "application/json", wantContentType: "application/problem+json", wantBody: `"code":"link_disabled"`},
+		{name: "browser", accept: "text/html,application/xhtml+xml", wantContentType: "text/html; charset=utf-8", wantBody: "<h1>Unavailable For Legal Reasons</h1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/bad123", nil)
+			req.Header.Set("Accept", tt.accept)
+			req.SetPathValue("code", "bad123")
+			w := httptest.NewRecorder()
+
+			handler.Get(w, req)
+
+			if w.Code != http.StatusUnavailableForLegalReasons {
+				t.Errorf("expected status %d, got %d", http.StatusUnavailableForLegalReasons, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("expected content type %q, got %q", tt.wantContentType, got)
+			}
+			if w.Header().Get("Location") != "" {
+				t.Error("expected no redirect")
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("expected body to contain %q, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	if len(spy.codes) != 0 {
+		t.Errorf("expected no clicks on a disabled link, got %v", spy.codes)
+	}
+}
+
 func TestHandlerShorten(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -99,7 +192,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "https://google.com"}`,
 			contentType:    "text/plain",
 			expectedStatus: http.StatusUnsupportedMediaType,
-			expectedInBody: "unsupported content type",
+			expectedInBody: `"code":"unsupported_media_type"`,
 			shouldError:    false,
 		},
 		{
@@ -107,7 +200,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "google.com"`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid request",
+			expectedInBody: `"code":"invalid_request_body"`,
 			shouldError:    false,
 		},
 		{
@@ -115,7 +208,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "https://google.com", "foo": "bar"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid request",
+			expectedInBody: `"code":"invalid_request_body"`,
 			shouldError:    false,
 		},
 		{
@@ -123,7 +216,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": ""}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid url",
+			expectedInBody: `"code":"invalid_url"`,
 			shouldError:    false,
 		},
 		{
@@ -131,7 +224,23 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "h tp://broken"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusBadRequest,
-			expectedInBody: "invalid url",
+			expectedInBody: `"code":"invalid_url"`,
+			shouldError:    false,
+		},
+		{
+			name:           "Unsupported Scheme",
+			reqBody:        `{"url": "javascript:alert(1)"}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"unsupported_url_scheme"`,
+			shouldError:    false,
+		},
+		{
+			name:           "Private Address",
+			reqBody:        `{"url": "http://169.254.169.254/latest/meta-data"}`,
+			contentType:    "application/json",
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedInBody: `"code":"private_url"`,
 			shouldError:    false,
 		},
 		{
@@ -139,7 +248,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "` + strings.Repeat("a", 2001) + `"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusUnprocessableEntity,
-			expectedInBody: "url too long",
+			expectedInBody: `"code":"url_too_long"`,
 			shouldError:    false,
 		},
 		{
@@ -147,7 +256,7 @@ func TestHandlerShorten(t *testing.T) {
 			reqBody:        `{"url": "https://google.com"}`,
 			contentType:    "application/json",
 			expectedStatus: http.StatusInternalServerError,
-			expectedInBody: "internal server error",
+			expectedInBody: `"code":"internal_error"`,
 			shouldError:    true,
 		},
 	}
@@ -159,8 +268,8 @@ func TestHandlerShorten(t *testing.T) {
 				repo.SetShouldError(true)
 			}
 
-			service := shortener.NewService(repo)
-			handler := shortener.NewHandler(service)
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+			handler := shortener.NewHandler(service, nil)
 
 			req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(tt.reqBody))
 			ctx := identity.WithUserID(context.Background(), "")
@@ -180,3 +289,134 @@ func TestHandlerShorten(t *testing.T) {
 		})
 	}
 }
+
+func TestHandlerShorten_Alias(t *testing.T) {
+	tests := []struct {
+		name           string
+		reqBody        string
+		userID         string
+		expectedStatus int
+		expectedInBody string
+	}{
+		{
+			name:           "Success",
+			reqBody:        `{"url": "https://google.com", "alias": "q4-launch"}`,
+			userID:         "123",
+			expectedStatus: http.StatusCreated,
+			expectedInBody: `"code":"q4-launch"`,
+		},
+		{
+			name:           "Conflict",
+			reqBody:        `{"url": "https://google.com", "alias": "taken"}`,
+			userID:         "123",
+			expectedStatus: http.StatusConflict,
+			expectedInBody: `"code":"alias_taken"`,
+		},
+		{
+			name:           "Reserved",
+			reqBody:        `{"url": "https://google.com", "alias": "api"}`,
+			userID:         "123",
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"alias_reserved"`,
+		},
+		{
+			name:           "Anonymous",
+			reqBody:        `{"url": "https://google.com", "alias": "q4-launch"}`,
+			userID:         "",
+			expectedStatus: http.StatusUnauthorized,
+			expectedInBody: `"code":"alias_requires_auth"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			_ = repo.save(context.Background(), &domain.PermanentLink{Code: "taken", OriginalURL: "https://other.com", UserID: "456"})
+
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+			handler := shortener.NewHandler(service, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/links", strings.NewReader(tt.reqBody))
+			req = req.WithContext(identity.WithUserID(context.Background(), tt.userID))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler.Shorten(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+
+			if !strings.Contains(w.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandlerUpdate(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           string
+		reqBody        string
+		userID         string
+		expectedStatus int
+		expectedInBody string
+	}{
+		{
+			name:           "Success",
+			code:           "upd123",
+			reqBody:        `{"url": "https://new.com"}`,
+			userID:         "user1",
+			expectedStatus: http.StatusOK,
+			expectedInBody: `"originalUrl":"https://new.com"`,
+		},
+		{
+			name:           "Not Owner",
+			code:           "upd123",
+			reqBody:        `{"url": "https://new.com"}`,
+			userID:         "user2",
+			expectedStatus: http.StatusForbidden,
+			expectedInBody: `"code":"link_update_forbidden"`,
+		},
+		{
+			name:           "Invalid URL",
+			code:           "upd123",
+			reqBody:        `{"url": "h tp://broken"}`,
+			userID:         "user1",
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"invalid_url"`,
+		},
+		{
+			name:           "Unknown Fields",
+			code:           "upd123",
+			reqBody:        `{"url": "https://new.com", "code": "other"}`,
+			userID:         "user1",
+			expectedStatus: http.StatusBadRequest,
+			expectedInBody: `"code":"invalid_request_body"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			_ = repo.save(context.Background(), &domain.PermanentLink{Code: "upd123", OriginalURL: "https://old.com", UserID: "user1"})
+			handler := shortener.NewHandler(shortener.NewService(repo, 24*time.Hour, nil), nil)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/links/"+tt.code, strings.NewReader(tt.reqBody))
+			req = req.WithContext(identity.WithUserID(context.Background(), tt.userID))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("code", tt.code)
+			w := httptest.NewRecorder()
+
+			handler.Update(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, w.Code)
+			}
+			if !strings.Contains(w.Body.String(), tt.expectedInBody) {
+				t.Errorf("expected body to contain %q, got %q", tt.expectedInBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
 )
 
 type LinkRepository interface {
@@ -13,10 +14,26 @@ type LinkRepository interface {
 	PermSave(ctx context.Context, link *domain.PermanentLink) error
 	Get(ctx context.Context, code string) (domain.Link, error)
 	Delete(ctx context.Context, code string, userId string) error
+	// DeleteAny deletes a link whoever owns it, temporary links included.
+	DeleteAny(ctx context.Context, code string) error
+	Update(ctx context.Context, code string, userID string, originalURL string) (*domain.PermanentLink, error)
+	// SetDisabled takes a link down or restores it, whoever owns it.
+	SetDisabled(ctx context.Context, code string, disabled bool) error
+	ListByUser(ctx context.Context, userID string, params ListParams) ([]*domain.PermanentLink, error)
+}
+
+type ListParams struct {
+	Limit       int
+	After       *pagination.Cursor
+	Ascending   bool
+	URLContains string
 }
 
 var ErrRecordNotFound = errors.New("record not found")
+var ErrRecordExpired = errors.New("record expired")
 var ErrRecordAlreadyExists = errors.New("record already exists")
 var ErrLimitExceeded = errors.New("user already exceeded link limit")
+var ErrOwnerNotFound = errors.New("link owner does not exist")
 var ErrNoLinkDeleted = errors.New("query did not delete any links")
+var ErrNoLinkUpdated = errors.New("query did not update any links")
 var ErrCouldNotUncache = errors.New("record was not deleted from redis")
@@ -8,21 +8,30 @@ import (
 	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/metrics"
+	"github.com/fernandesenzo/shortener/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type HybridLinkRepository struct {
 	postgres *PostgresRepository
 	redis    *RedisRepository
+	cacheTTL time.Duration
 }
 
-func NewHybridLinkRepository(postgres *PostgresRepository, redis *RedisRepository) *HybridLinkRepository {
+// NewHybridLinkRepository returns a repository caching permanent links in redis for at most cacheTTL.
+func NewHybridLinkRepository(postgres *PostgresRepository, redis *RedisRepository, cacheTTL time.Duration) *HybridLinkRepository {
 	return &HybridLinkRepository{
 		postgres: postgres,
 		redis:    redis,
+		cacheTTL: cacheTTL,
 	}
 }
 
-func (r *HybridLinkRepository) TempSave(ctx context.Context, link *domain.TemporaryLink, ttl time.Duration) error {
+func (r *HybridLinkRepository) TempSave(ctx context.Context, link *domain.TemporaryLink, ttl time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.TempSave", attribute.String("link.code", link.Code))
+	defer func() { tracing.End(span, err, ErrRecordAlreadyExists) }()
+
 	linkExists, err := r.exists(ctx, link.Code)
 	if err != nil {
 		return err
@@ -32,12 +41,16 @@ func (r *HybridLinkRepository) TempSave(ctx context.Context, link *domain.Tempor
 	}
 	err = r.redis.Save(ctx, link, ttl)
 	if err != nil {
+		metrics.IncBackendError(metrics.BackendRedis, "save")
 		return err
 	}
 	return nil
 }
 
-func (r *HybridLinkRepository) PermSave(ctx context.Context, link *domain.PermanentLink) error {
+func (r *HybridLinkRepository) PermSave(ctx context.Context, link *domain.PermanentLink) (err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.PermSave", attribute.String("link.code", link.Code))
+	defer func() { tracing.End(span, err, ErrRecordAlreadyExists, ErrLimitExceeded, ErrOwnerNotFound) }()
+
 	linkExists, err := r.exists(ctx, link.Code)
 	if err != nil {
 		return err
@@ -47,59 +60,165 @@ func (r *HybridLinkRepository) PermSave(ctx context.Context, link *domain.Perman
 	}
 	err = r.postgres.Save(ctx, link)
 	if err != nil {
+		if !errors.Is(err, ErrRecordAlreadyExists) && !errors.Is(err, ErrLimitExceeded) && !errors.Is(err, ErrOwnerNotFound) {
+			metrics.IncBackendError(metrics.BackendPostgres, "save")
+		}
 		return err
 	}
-	if err = r.redis.Save(ctx, &domain.TemporaryLink{
-		OriginalURL: link.OriginalURL,
-		Code:        link.Code,
-	}, 24*time.Hour); err != nil {
+	if err = r.cache(ctx, link); err != nil {
 		slog.WarnContext(ctx, "error caching permanent link", "code", link.Code)
 	}
 
 	return nil
 }
 
-func (r *HybridLinkRepository) Get(ctx context.Context, code string) (domain.Link, error) {
+func (r *HybridLinkRepository) Get(ctx context.Context, code string) (_ domain.Link, err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.Get", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrRecordNotFound, ErrRecordExpired) }()
+
 	link, err := r.redis.Get(ctx, code)
 	if err != nil {
 		if !errors.Is(err, ErrRecordNotFound) {
 			slog.ErrorContext(ctx, "redis error", "err", err)
+			metrics.IncBackendError(metrics.BackendRedis, "get")
 		}
 	} else {
+		metrics.ObserveCacheLookup(true)
 		return link, nil
 	}
+	metrics.ObserveCacheLookup(false)
 
 	linkdb, err := r.postgres.Get(ctx, code)
 	if err != nil {
 		if errors.Is(err, ErrRecordNotFound) {
 			return nil, err
 		}
+		metrics.IncBackendError(metrics.BackendPostgres, "get")
 		return nil, fmt.Errorf("error obtaining link from postgres: %w", err)
 	}
+	if linkdb.IsExpired(time.Now()) {
+		return nil, ErrRecordExpired
+	}
 
-	_ = r.redis.Save(ctx, &domain.TemporaryLink{
-		Code:        linkdb.GetCode(),
-		OriginalURL: linkdb.GetOriginalURL(),
-	}, 24*time.Hour)
+	_ = r.cache(ctx, linkdb)
 
 	return linkdb, nil
 }
 
-func (r *HybridLinkRepository) Delete(ctx context.Context, code string, userId string) error {
+func (r *HybridLinkRepository) Delete(ctx context.Context, code string, userId string) (err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.Delete", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkDeleted) }()
+
 	if err := r.postgres.Delete(ctx, code, userId); err != nil {
+		if !errors.Is(err, ErrNoLinkDeleted) {
+			metrics.IncBackendError(metrics.BackendPostgres, "delete")
+		}
 		return err
 	}
 	if err := r.redis.Delete(ctx, code); err != nil {
 		if !errors.Is(err, ErrRecordNotFound) {
+			metrics.IncBackendError(metrics.BackendRedis, "delete")
 			return err
 		}
 	}
 	return nil
 }
 
+// DeleteAny falls back to redis for codes unknown to postgres, which are temporary links.
+func (r *HybridLinkRepository) DeleteAny(ctx context.Context, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.DeleteAny", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkDeleted) }()
+
+	err = r.postgres.DeleteAny(ctx, code)
+	switch {
+	case errors.Is(err, ErrNoLinkDeleted):
+		// unknown to postgres, so it can only be a temporary link
+		if _, err := r.redis.Get(ctx, code); err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrNoLinkDeleted
+			}
+			metrics.IncBackendError(metrics.BackendRedis, "get")
+			return err
+		}
+	case err != nil:
+		metrics.IncBackendError(metrics.BackendPostgres, "delete")
+		return err
+	}
+	if err := r.redis.Delete(ctx, code); err != nil {
+		metrics.IncBackendError(metrics.BackendRedis, "delete")
+		return err
+	}
+	return nil
+}
+
+func (r *HybridLinkRepository) Update(ctx context.Context, code string, userID string, originalURL string) (_ *domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.Update", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkUpdated) }()
+
+	link, err := r.postgres.Update(ctx, code, userID, originalURL)
+	if err != nil {
+		if !errors.Is(err, ErrNoLinkUpdated) {
+			metrics.IncBackendError(metrics.BackendPostgres, "update")
+		}
+		return nil, err
+	}
+	if err := r.cache(ctx, link); err != nil {
+		metrics.IncBackendError(metrics.BackendRedis, "save")
+		slog.WarnContext(ctx, "error refreshing cached link, evicting it", "code", code, "error", err)
+		if err := r.redis.Delete(ctx, code); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrCouldNotUncache, err)
+		}
+	}
+	return link, nil
+}
+
+// SetDisabled updates postgres and the cached copy together, so redirects served
+// from the cache stop as soon as a link is disabled. Codes unknown to postgres
+// are temporary links, which only live in redis.
+func (r *HybridLinkRepository) SetDisabled(ctx context.Context, code string, disabled bool) (err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.SetDisabled", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkUpdated) }()
+
+	link, err := r.postgres.SetDisabled(ctx, code, disabled)
+	switch {
+	case errors.Is(err, ErrNoLinkUpdated):
+		if err := r.redis.SetDisabled(ctx, code, disabled); err != nil {
+			if errors.Is(err, ErrRecordNotFound) {
+				return ErrNoLinkUpdated
+			}
+			metrics.IncBackendError(metrics.BackendRedis, "save")
+			return err
+		}
+		return nil
+	case err != nil:
+		metrics.IncBackendError(metrics.BackendPostgres, "update")
+		return err
+	}
+	if err := r.cache(ctx, link); err != nil {
+		metrics.IncBackendError(metrics.BackendRedis, "save")
+		slog.WarnContext(ctx, "error refreshing cached link, evicting it", "code", code, "error", err)
+		if err := r.redis.Delete(ctx, code); err != nil {
+			return fmt.Errorf("%w: %w", ErrCouldNotUncache, err)
+		}
+	}
+	return nil
+}
+
+func (r *HybridLinkRepository) ListByUser(ctx context.Context, userID string, params ListParams) (_ []*domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "HybridLinkRepository.ListByUser")
+	defer func() { tracing.End(span, err) }()
+
+	links, err := r.postgres.ListByUser(ctx, userID, params)
+	if err != nil {
+		metrics.IncBackendError(metrics.BackendPostgres, "list")
+	}
+	return links, err
+}
+
 func (r *HybridLinkRepository) exists(ctx context.Context, code string) (bool, error) {
 	exists, err := r.postgres.Exists(ctx, code)
 	if err != nil {
+		metrics.IncBackendError(metrics.BackendPostgres, "exists")
 		return false, err
 	}
 	if exists {
@@ -114,5 +233,22 @@ func (r *HybridLinkRepository) exists(ctx context.Context, code string) (bool, e
 		return false, nil
 	}
 
+	metrics.IncBackendError(metrics.BackendRedis, "exists")
 	return false, err
 }
+
+// cache stores a permanent link in redis without letting the entry outlive the link itself.
+func (r *HybridLinkRepository) cache(ctx context.Context, link *domain.PermanentLink) error {
+	ttl := r.cacheTTL
+	if link.ExpiresAt != nil {
+		ttl = min(ttl, time.Until(*link.ExpiresAt))
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	return r.redis.Save(ctx, &domain.TemporaryLink{
+		Code:        link.Code,
+		OriginalURL: link.OriginalURL,
+		Disabled:    link.IsDisabled(),
+	}, ttl)
+}
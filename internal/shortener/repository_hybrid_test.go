@@ -16,13 +16,13 @@ import (
 func TestHybridRepository(t *testing.T) {
 	db, cleanup := testutil.SetupTestDB(t)
 	defer cleanup()
-	pgRepo := shortener.NewPostgresRepository(db)
+	pgRepo := shortener.NewPostgresRepository(db, 10)
 
 	mr := miniredis.RunT(t)
 	redisClient := redis.NewClient(&redis.Options{Addr: mr.Addr()})
 	redisRepo := shortener.NewRedisRepository(redisClient)
 
-	hybrid := shortener.NewHybridLinkRepository(pgRepo, redisRepo)
+	hybrid := shortener.NewHybridLinkRepository(pgRepo, redisRepo, 24*time.Hour)
 	ctx := context.Background()
 
 	var testUserID string
@@ -136,6 +136,61 @@ func TestHybridRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("Expiration", func(t *testing.T) {
+		soon := time.Now().Add(time.Minute)
+		err := hybrid.PermSave(ctx, &domain.PermanentLink{
+			Code:        "EXPSOON",
+			OriginalURL: "https://soon.com",
+			UserID:      testUserID,
+			ExpiresAt:   &soon,
+		})
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		if ttl := mr.TTL("link:EXPSOON"); ttl <= 0 || ttl > time.Minute {
+			t.Errorf("expected cache ttl bounded by expiration, got %v", ttl)
+		}
+
+		past := time.Now().Add(-time.Minute)
+		_, err = db.Exec(`INSERT INTO links (code, original_url, user_id, expires_at) VALUES ($1, $2, $3, $4)`,
+			"EXPIRED", "https://expired.com", testUserID, past)
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+
+		_, err = hybrid.Get(ctx, "EXPIRED")
+		if !errors.Is(err, shortener.ErrRecordExpired) {
+			t.Errorf("expected %v, got %v", shortener.ErrRecordExpired, err)
+		}
+		if mr.Exists("link:EXPIRED") {
+			t.Error("expected expired link not to be cached")
+		}
+	})
+
+	t.Run("Update_Hybrid_Flow", func(t *testing.T) {
+		code := "UPDHYB"
+		err := hybrid.PermSave(ctx, &domain.PermanentLink{
+			Code:        code,
+			OriginalURL: "https://old.com",
+			UserID:      testUserID,
+		})
+		if err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+
+		_, err = hybrid.Update(ctx, code, otherUserID, "https://hijack.com")
+		if !errors.Is(err, shortener.ErrNoLinkUpdated) {
+			t.Errorf("expected %v, got %v", shortener.ErrNoLinkUpdated, err)
+		}
+
+		if _, err := hybrid.Update(ctx, code, testUserID, "https://new.com"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cached, _ := mr.Get("link:" + code); cached != "https://new.com" {
+			t.Errorf("expected cache to hold the new url, got %q", cached)
+		}
+	})
+
 	t.Run("Delete_Hybrid_Flow", func(t *testing.T) {
 		code := "DELHYB"
 		url := "https://delete-hybrid.com"
@@ -187,4 +242,65 @@ func TestHybridRepository(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("DeleteAny_Hybrid_Flow", func(t *testing.T) {
+		if err := hybrid.PermSave(ctx, &domain.PermanentLink{Code: "ANYPERM", OriginalURL: "https://perm.com", UserID: testUserID}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		_ = mr.Set("link:ANYPERM", "https://perm.com")
+		if err := hybrid.TempSave(ctx, &domain.TemporaryLink{Code: "ANYTEMP", OriginalURL: "https://temp.com"}, time.Hour); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+
+		for _, code := range []string{"ANYPERM", "ANYTEMP"} {
+			if err := hybrid.DeleteAny(ctx, code); err != nil {
+				t.Errorf("DeleteAny(%s) error = %v", code, err)
+			}
+			if mr.Exists("link:" + code) {
+				t.Errorf("expected %s to be removed from redis", code)
+			}
+		}
+		if err := hybrid.DeleteAny(ctx, "ANYPERM"); !errors.Is(err, shortener.ErrNoLinkDeleted) {
+			t.Errorf("expected %v, got %v", shortener.ErrNoLinkDeleted, err)
+		}
+	})
+
+	t.Run("SetDisabled_Hybrid_Flow", func(t *testing.T) {
+		if err := hybrid.PermSave(ctx, &domain.PermanentLink{Code: "MODPERM", OriginalURL: "https://perm.com", UserID: testUserID}); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+		if err := hybrid.TempSave(ctx, &domain.TemporaryLink{Code: "MODTEMP", OriginalURL: "https://temp.com"}, time.Hour); err != nil {
+			t.Fatalf("setup failed: %v", err)
+		}
+
+		for _, code := range []string{"MODPERM", "MODTEMP"} {
+			if err := hybrid.SetDisabled(ctx, code, true); err != nil {
+				t.Fatalf("SetDisabled(%s) error = %v", code, err)
+			}
+			link, err := hybrid.Get(ctx, code)
+			if err != nil {
+				t.Fatalf("Get(%s) error = %v", code, err)
+			}
+			if !link.IsDisabled() {
+				t.Errorf("expected %s to be disabled", code)
+			}
+		}
+
+		// a cache miss must still see the state stored in postgres
+		mr.Del("link:MODPERM")
+		link, err := hybrid.Get(ctx, "MODPERM")
+		if err != nil || !link.IsDisabled() {
+			t.Errorf("expected MODPERM to stay disabled after a cache miss, got %v, %v", link, err)
+		}
+
+		if err := hybrid.SetDisabled(ctx, "MODPERM", false); err != nil {
+			t.Fatalf("SetDisabled() error = %v", err)
+		}
+		if val, _ := mr.Get("link:MODPERM"); val != "https://perm.com" {
+			t.Errorf("expected the cache to be enabled again, got %q", val)
+		}
+		if err := hybrid.SetDisabled(ctx, "MISSING", true); !errors.Is(err, shortener.ErrNoLinkUpdated) {
+			t.Errorf("expected %v, got %v", shortener.ErrNoLinkUpdated, err)
+		}
+	})
 }
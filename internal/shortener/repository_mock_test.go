@@ -3,6 +3,8 @@ package shortener_test
 import (
 	"context"
 	"errors"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
@@ -15,6 +17,7 @@ type MockRepository struct {
 	collisionCounter int
 	tempSaveCalled   bool
 	permSaveCalled   bool
+	ownerMissing     bool
 }
 
 func (m *MockRepository) TempSave(ctx context.Context, link *domain.TemporaryLink, ttl time.Duration) error {
@@ -24,6 +27,9 @@ func (m *MockRepository) TempSave(ctx context.Context, link *domain.TemporaryLin
 
 func (m *MockRepository) PermSave(ctx context.Context, link *domain.PermanentLink) error {
 	m.permSaveCalled = true
+	if m.ownerMissing {
+		return shortener.ErrOwnerNotFound
+	}
 	return m.save(ctx, link)
 }
 
@@ -31,11 +37,14 @@ func (m *MockRepository) Get(_ context.Context, code string) (domain.Link, error
 	if m.shouldError {
 		return nil, errors.New("simulated error")
 	}
-	_, exists := m.items[code]
+	link, exists := m.items[code]
 	if !exists {
 		return nil, shortener.ErrRecordNotFound
 	}
-	return m.items[code], nil
+	if permLink, ok := link.(*domain.PermanentLink); ok && permLink.IsExpired(time.Now()) {
+		return nil, shortener.ErrRecordExpired
+	}
+	return link, nil
 }
 
 func (m *MockRepository) Delete(ctx context.Context, code string, userID string) error {
@@ -64,6 +73,95 @@ func (m *MockRepository) Delete(ctx context.Context, code string, userID string)
 	return nil
 }
 
+func (m *MockRepository) DeleteAny(_ context.Context, code string) error {
+	if m.shouldError {
+		return errors.New("simulated error")
+	}
+	if _, exists := m.items[code]; !exists {
+		return shortener.ErrNoLinkDeleted
+	}
+	delete(m.items, code)
+	return nil
+}
+
+func (m *MockRepository) Update(_ context.Context, code string, userID string, originalURL string) (*domain.PermanentLink, error) {
+	if m.shouldError {
+		return nil, errors.New("simulated error")
+	}
+	link, ok := m.items[code].(*domain.PermanentLink)
+	if !ok || link.UserID != userID {
+		return nil, shortener.ErrNoLinkUpdated
+	}
+	link.OriginalURL = originalURL
+	return link, nil
+}
+
+func (m *MockRepository) SetDisabled(_ context.Context, code string, disabled bool) error {
+	if m.shouldError {
+		return errors.New("simulated error")
+	}
+	switch link := m.items[code].(type) {
+	case *domain.PermanentLink:
+		if !disabled {
+			link.DisabledAt = nil
+		} else if link.DisabledAt == nil {
+			now := time.Now()
+			link.DisabledAt = &now
+		}
+	case *domain.TemporaryLink:
+		link.Disabled = disabled
+	default:
+		return shortener.ErrNoLinkUpdated
+	}
+	return nil
+}
+
+func (m *MockRepository) ListByUser(_ context.Context, userID string, params shortener.ListParams) ([]*domain.PermanentLink, error) {
+	if m.shouldError {
+		return nil, errors.New("simulated error")
+	}
+
+	var links []*domain.PermanentLink
+	for _, item := range m.items {
+		link, ok := item.(*domain.PermanentLink)
+		if !ok || link.UserID != userID {
+			continue
+		}
+		if !strings.Contains(strings.ToLower(link.OriginalURL), strings.ToLower(params.URLContains)) {
+			continue
+		}
+		links = append(links, link)
+	}
+
+	less := func(a, b *domain.PermanentLink) bool {
+		if a.CreatedAt.Equal(b.CreatedAt) {
+			return a.ID < b.ID
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if params.Ascending {
+			return less(links[i], links[j])
+		}
+		return less(links[j], links[i])
+	})
+
+	result := make([]*domain.PermanentLink, 0, params.Limit)
+	for _, link := range links {
+		if params.After != nil {
+			after := &domain.PermanentLink{ID: params.After.ID, CreatedAt: params.After.CreatedAt}
+			if params.Ascending && !less(after, link) || !params.Ascending && !less(link, after) {
+				continue
+			}
+		}
+		if len(result) == params.Limit {
+			break
+		}
+		result = append(result, link)
+	}
+	return result, nil
+}
+
 func (m *MockRepository) SetShouldError(shouldError bool) {
 	m.shouldError = shouldError
 }
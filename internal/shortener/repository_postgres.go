@@ -5,33 +5,59 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/tracing"
 	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type PostgresRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	defaultQuota int
 }
 
-func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+func NewPostgresRepository(db *sql.DB, defaultQuota int) *PostgresRepository {
 	return &PostgresRepository{
-		db}
+		db:           db,
+		defaultQuota: defaultQuota,
+	}
 }
 
-func (r *PostgresRepository) Save(ctx context.Context, link *domain.PermanentLink) error {
-	query := `
-        INSERT INTO links (code, original_url, user_id)
-        SELECT $1, $2, $3
-        WHERE (SELECT COUNT(*) FROM links WHERE user_id = $3) < 10
-        RETURNING id, created_at`
+// Save locks the owner's row so concurrent inserts for the same user cannot exceed the quota.
+func (r *PostgresRepository) Save(ctx context.Context, link *domain.PermanentLink) (err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.Save", attribute.String("link.code", link.Code))
+	defer func() { tracing.End(span, err, ErrRecordAlreadyExists, ErrLimitExceeded, ErrOwnerNotFound) }()
 
-	err := r.db.QueryRowContext(ctx, query, link.Code, link.OriginalURL, link.UserID).Scan(&link.ID, &link.CreatedAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
 
+	var quota, used int
+	err = tx.QueryRowContext(ctx, `SELECT COALESCE(link_quota, $2) FROM users WHERE id = $1 FOR UPDATE`, link.UserID, r.defaultQuota).Scan(&quota)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return ErrLimitExceeded
+			return ErrOwnerNotFound
 		}
+		return fmt.Errorf("error locking link owner: %w", err)
+	}
+	if err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM links WHERE user_id = $1`, link.UserID).Scan(&used); err != nil {
+		return fmt.Errorf("error counting user links: %w", err)
+	}
+	if used >= quota {
+		return ErrLimitExceeded
+	}
+
+	query := `
+        INSERT INTO links (code, original_url, user_id, expires_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at`
+
+	err = tx.QueryRowContext(ctx, query, link.Code, link.OriginalURL, link.UserID, link.ExpiresAt).Scan(&link.ID, &link.CreatedAt)
+	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) {
 			if pqErr.Code == "23505" {
@@ -42,14 +68,16 @@ func (r *PostgresRepository) Save(ctx context.Context, link *domain.PermanentLin
 
 		return err
 	}
-	return nil
+	return tx.Commit()
 }
-func (r *PostgresRepository) Get(ctx context.Context, code string) (*domain.PermanentLink, error) {
-	query := `SELECT id, code, original_url, created_at, user_id FROM links WHERE code = $1`
 
-	var link domain.PermanentLink
-	err := r.db.QueryRowContext(ctx, query, code).Scan(&link.ID, &link.Code, &link.OriginalURL, &link.CreatedAt, &link.UserID)
+func (r *PostgresRepository) Get(ctx context.Context, code string) (_ *domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.Get", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrRecordNotFound) }()
 
+	query := `SELECT ` + linkColumns + ` FROM links WHERE code = $1`
+
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, code))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrRecordNotFound
@@ -63,14 +91,17 @@ func (r *PostgresRepository) Get(ctx context.Context, code string) (*domain.Perm
 		return nil, fmt.Errorf("unexpected error getting link: %w", err)
 	}
 
-	return &link, nil
+	return link, nil
 }
 
-func (r *PostgresRepository) Exists(ctx context.Context, code string) (bool, error) {
+func (r *PostgresRepository) Exists(ctx context.Context, code string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.Exists", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err) }()
+
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM links WHERE code = $1)`
 
-	err := r.db.QueryRowContext(ctx, query, code).Scan(&exists)
+	err = r.db.QueryRowContext(ctx, query, code).Scan(&exists)
 	if err != nil {
 		var pgErr *pq.Error
 		if errors.As(err, &pgErr) {
@@ -83,7 +114,10 @@ func (r *PostgresRepository) Exists(ctx context.Context, code string) (bool, err
 	return exists, nil
 }
 
-func (r *PostgresRepository) Delete(ctx context.Context, code string, userID string) error {
+func (r *PostgresRepository) Delete(ctx context.Context, code string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.Delete", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkDeleted) }()
+
 	res, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE code = $1 AND user_id = $2", code, userID)
 	if err != nil {
 		return err
@@ -97,3 +131,130 @@ func (r *PostgresRepository) Delete(ctx context.Context, code string, userID str
 	}
 	return nil
 }
+
+func (r *PostgresRepository) DeleteAny(ctx context.Context, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.DeleteAny", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkDeleted) }()
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM links WHERE code = $1", code)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrNoLinkDeleted
+	}
+	return nil
+}
+
+func (r *PostgresRepository) Update(ctx context.Context, code string, userID string, originalURL string) (_ *domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.Update", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkUpdated) }()
+
+	query := `
+        UPDATE links SET original_url = $3
+        WHERE code = $1 AND user_id = $2
+        RETURNING ` + linkColumns
+
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, code, userID, originalURL))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoLinkUpdated
+		}
+		return nil, fmt.Errorf("error updating link: %w", err)
+	}
+
+	return link, nil
+}
+
+// SetDisabled disables a link or enables it again, whoever owns it. Disabling an
+// already disabled link keeps the original date.
+func (r *PostgresRepository) SetDisabled(ctx context.Context, code string, disabled bool) (_ *domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.SetDisabled", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrNoLinkUpdated) }()
+
+	query := `
+        UPDATE links SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, now()) END
+        WHERE code = $1
+        RETURNING ` + linkColumns
+
+	link, err := scanLink(r.db.QueryRowContext(ctx, query, code, disabled))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNoLinkUpdated
+		}
+		return nil, fmt.Errorf("error disabling link: %w", err)
+	}
+
+	return link, nil
+}
+
+func (r *PostgresRepository) ListByUser(ctx context.Context, userID string, params ListParams) (_ []*domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "PostgresRepository.ListByUser")
+	defer func() { tracing.End(span, err) }()
+
+	query := `SELECT ` + linkColumns + ` FROM links WHERE user_id = $1`
+	args := []any{userID}
+
+	if params.URLContains != "" {
+		args = append(args, "%"+escapeLike(params.URLContains)+"%")
+		query += fmt.Sprintf(" AND original_url ILIKE $%d", len(args))
+	}
+
+	op, order := "<", "DESC"
+	if params.Ascending {
+		op, order = ">", "ASC"
+	}
+	if params.After != nil {
+		args = append(args, params.After.CreatedAt, params.After.ID)
+		query += fmt.Sprintf(" AND (created_at, id) %s ($%d, $%d)", op, len(args)-1, len(args))
+	}
+
+	args = append(args, params.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT $%d", order, order, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing links: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]*domain.PermanentLink, 0, params.Limit)
+	for rows.Next() {
+		link, err := scanLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning link: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating links: %w", err)
+	}
+
+	return links, nil
+}
+
+const linkColumns = `id, code, original_url, created_at, user_id, expires_at, disabled_at`
+
+// scanLink reads a row selected with linkColumns.
+func scanLink(row interface{ Scan(dest ...any) error }) (*domain.PermanentLink, error) {
+	var link domain.PermanentLink
+	var expiresAt, disabledAt sql.NullTime
+	if err := row.Scan(&link.ID, &link.Code, &link.OriginalURL, &link.CreatedAt, &link.UserID, &expiresAt, &disabledAt); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		link.ExpiresAt = &expiresAt.Time
+	}
+	if disabledAt.Valid {
+		link.DisabledAt = &disabledAt.Time
+	}
+	return &link, nil
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -3,9 +3,12 @@ package shortener_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
 	"github.com/fernandesenzo/shortener/internal/shortener"
 	"github.com/fernandesenzo/shortener/internal/testutil"
 	_ "github.com/lib/pq"
@@ -15,7 +18,7 @@ func TestPostgresRepository(t *testing.T) {
 	db, cleanup := testutil.SetupTestDB(t)
 	defer cleanup()
 
-	repo := shortener.NewPostgresRepository(db)
+	repo := shortener.NewPostgresRepository(db, 10)
 	ctx := context.Background()
 
 	query := `
@@ -59,6 +62,24 @@ func TestPostgresRepository(t *testing.T) {
 				},
 				wantErr: shortener.ErrRecordAlreadyExists,
 			},
+			{
+				name: "success saving custom alias longer than generated codes",
+				link: &domain.PermanentLink{
+					Code:        "q4-launch-campaign",
+					OriginalURL: "https://github.com",
+					UserID:      userID,
+				},
+				wantErr: nil,
+			},
+			{
+				name: "fail when the owner does not exist",
+				link: &domain.PermanentLink{
+					Code:        "orphan",
+					OriginalURL: "https://github.com",
+					UserID:      "00000000-0000-0000-0000-000000000000",
+				},
+				wantErr: shortener.ErrOwnerNotFound,
+			},
 		}
 
 		for _, tt := range tests {
@@ -201,4 +222,112 @@ func TestPostgresRepository(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("SetDisabled", func(t *testing.T) {
+		_ = repo.Save(ctx, &domain.PermanentLink{Code: "DISABLE", OriginalURL: "https://disable.com", UserID: userID})
+
+		link, err := repo.SetDisabled(ctx, "DISABLE", true)
+		if err != nil {
+			t.Fatalf("SetDisabled() error = %v", err)
+		}
+		if link.DisabledAt == nil {
+			t.Fatal("expected disabled_at to be set")
+		}
+		again, _ := repo.SetDisabled(ctx, "DISABLE", true)
+		if again == nil || !again.DisabledAt.Equal(*link.DisabledAt) {
+			t.Errorf("expected disabling twice to keep the original date, got %v", again)
+		}
+		if got, _ := repo.Get(ctx, "DISABLE"); got == nil || !got.IsDisabled() {
+			t.Errorf("expected Get to report the link as disabled, got %v", got)
+		}
+
+		link, err = repo.SetDisabled(ctx, "DISABLE", false)
+		if err != nil || link.DisabledAt != nil {
+			t.Errorf("expected the link to be enabled again, got %v, %v", link, err)
+		}
+		if _, err := repo.SetDisabled(ctx, "ghost_code", true); !errors.Is(err, shortener.ErrNoLinkUpdated) {
+			t.Errorf("expected %v, got %v", shortener.ErrNoLinkUpdated, err)
+		}
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		listUserID := ""
+		err := db.QueryRow(query, "listuser", "hashedpassword").Scan(&listUserID)
+		if err != nil {
+			t.Fatalf("error inserting list user: %v", err)
+		}
+		for _, link := range []*domain.PermanentLink{
+			{Code: "list01", OriginalURL: "https://example.com/a", UserID: listUserID},
+			{Code: "list02", OriginalURL: "https://example.com/100%_off", UserID: listUserID},
+			{Code: "list03", OriginalURL: "https://other.com", UserID: listUserID},
+		} {
+			if err := repo.Save(ctx, link); err != nil {
+				t.Fatalf("error seeding link: %v", err)
+			}
+		}
+
+		first, err := repo.ListByUser(ctx, listUserID, shortener.ListParams{Limit: 2})
+		if err != nil {
+			t.Fatalf("ListByUser() error = %v", err)
+		}
+		if len(first) != 2 || first[0].Code != "list03" {
+			t.Fatalf("unexpected first page: %+v", first)
+		}
+
+		cursor := &pagination.Cursor{CreatedAt: first[1].CreatedAt, ID: first[1].ID}
+		second, err := repo.ListByUser(ctx, listUserID, shortener.ListParams{Limit: 2, After: cursor})
+		if err != nil {
+			t.Fatalf("ListByUser() error = %v", err)
+		}
+		if len(second) != 1 || second[0].Code != "list01" {
+			t.Fatalf("unexpected second page: %+v", second)
+		}
+
+		filtered, err := repo.ListByUser(ctx, listUserID, shortener.ListParams{Limit: 10, URLContains: "%_"})
+		if err != nil {
+			t.Fatalf("ListByUser() error = %v", err)
+		}
+		if len(filtered) != 1 || filtered[0].Code != "list02" {
+			t.Errorf("expected only list02 to match literal wildcard characters, got %+v", filtered)
+		}
+	})
+
+	t.Run("Quota", func(t *testing.T) {
+		var quotaUserID string
+		err := db.QueryRow(`INSERT INTO users (nickname, password_hash, link_quota) VALUES ('quotauser', 'hash', 3) RETURNING id`).Scan(&quotaUserID)
+		if err != nil {
+			t.Fatalf("error inserting quota user: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		results := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				results <- repo.Save(ctx, &domain.PermanentLink{
+					Code:        fmt.Sprintf("quota%d", i),
+					OriginalURL: "https://quota.com",
+					UserID:      quotaUserID,
+				})
+			}(i)
+		}
+		wg.Wait()
+		close(results)
+
+		saved, exceeded := 0, 0
+		for err := range results {
+			switch {
+			case err == nil:
+				saved++
+			case errors.Is(err, shortener.ErrLimitExceeded):
+				exceeded++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}
+		if saved != 3 || exceeded != 7 {
+			t.Errorf("expected 3 saved and 7 rejected under concurrency, got %d saved and %d rejected", saved, exceeded)
+		}
+	})
 }
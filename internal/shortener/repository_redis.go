@@ -4,14 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/tracing"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const linkPrefix = "link:"
 
+// disabledMarker prefixes the cached URL of disabled links. Valid URLs cannot
+// start with it, so entries written before moderation existed still read as enabled.
+const disabledMarker = "!"
+
 type RedisRepository struct {
 	client *redis.Client
 }
@@ -19,38 +26,97 @@ type RedisRepository struct {
 func NewRedisRepository(client *redis.Client) *RedisRepository {
 	return &RedisRepository{client}
 }
-func (r *RedisRepository) Save(ctx context.Context, link *domain.TemporaryLink, ttl time.Duration) error {
+func (r *RedisRepository) Save(ctx context.Context, link *domain.TemporaryLink, ttl time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisRepository.Save", attribute.String("link.code", link.Code))
+	defer func() { tracing.End(span, err) }()
+
 	key := linkPrefix + link.Code
 
-	_, err := r.client.Set(ctx, key, link.OriginalURL, ttl).Result()
+	_, err = r.client.Set(ctx, key, encodeValue(link), ttl).Result()
 	if err != nil {
 		return fmt.Errorf("redis set error: %w", err)
 	}
 	return nil
 }
 
-func (r *RedisRepository) Get(ctx context.Context, code string) (*domain.TemporaryLink, error) {
+func (r *RedisRepository) Get(ctx context.Context, code string) (_ *domain.TemporaryLink, err error) {
+	ctx, span := tracing.Start(ctx, "RedisRepository.Get", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrRecordNotFound) }()
+
 	key := linkPrefix + code
 
-	url, err := r.client.Get(ctx, key).Result()
+	value, err := r.client.Get(ctx, key).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			return nil, ErrRecordNotFound
 		}
 		return nil, fmt.Errorf("unexpected error when getting from redis: %w", err)
 	}
+	url, disabled := strings.CutPrefix(value, disabledMarker)
 	return &domain.TemporaryLink{
 		Code:        code,
 		OriginalURL: url,
+		Disabled:    disabled,
 	}, nil
 }
 
-func (r *RedisRepository) Delete(ctx context.Context, code string) error {
+// SetDisabled flags a cached link as disabled or enabled again without touching
+// its expiration. Links missing from the cache report ErrRecordNotFound.
+func (r *RedisRepository) SetDisabled(ctx context.Context, code string, disabled bool) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisRepository.SetDisabled", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, ErrRecordNotFound) }()
+
+	link, err := r.Get(ctx, code)
+	if err != nil {
+		return err
+	}
+	link.Disabled = disabled
+
+	// XX keeps a link that expired in between from coming back to life
+	err = r.client.SetArgs(ctx, linkPrefix+code, encodeValue(link), redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("redis set error: %w", err)
+	}
+	return nil
+}
+
+func (r *RedisRepository) Delete(ctx context.Context, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisRepository.Delete", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err) }()
+
 	key := linkPrefix + code
-	err := r.client.Del(ctx, key).Err()
+	err = r.client.Del(ctx, key).Err()
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+func encodeValue(link *domain.TemporaryLink) string {
+	if link.Disabled {
+		return disabledMarker + link.OriginalURL
+	}
+	return link.OriginalURL
+}
+
+// Purge drops the cached entries of the given links.
+func (r *RedisRepository) Purge(ctx context.Context, codes ...string) (err error) {
+	ctx, span := tracing.Start(ctx, "RedisRepository.Purge", attribute.Int("link.count", len(codes)))
+	defer func() { tracing.End(span, err) }()
+
+	if len(codes) == 0 {
+		return nil
+	}
+	keys := make([]string, len(codes))
+	for i, code := range codes {
+		keys[i] = linkPrefix + code
+	}
+	if err = r.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("redis del error: %w", err)
+	}
+	return nil
+}
@@ -126,3 +126,65 @@ func TestRedisRepository_Table(t *testing.T) {
 		}
 	})
 }
+
+func TestRedisRepository_Purge(t *testing.T) {
+	s := miniredis.RunT(t)
+	repo := NewRedisRepository(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	ctx := context.Background()
+
+	for _, code := range []string{"a", "b", "c"} {
+		if err := repo.Save(ctx, &domain.TemporaryLink{Code: code, OriginalURL: "https://test.com"}, time.Hour); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	if err := repo.Purge(ctx); err != nil {
+		t.Fatalf("Purge() without codes error = %v", err)
+	}
+	if err := repo.Purge(ctx, "a", "b", "missing"); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	for code, want := range map[string]bool{"a": false, "b": false, "c": true} {
+		if got := s.Exists(linkPrefix + code); got != want {
+			t.Errorf("link %s cached = %v, want %v", code, got, want)
+		}
+	}
+}
+
+func TestRedisRepository_SetDisabled(t *testing.T) {
+	s := miniredis.RunT(t)
+	repo := NewRedisRepository(redis.NewClient(&redis.Options{Addr: s.Addr()}))
+	ctx := context.Background()
+
+	if err := repo.Save(ctx, &domain.TemporaryLink{Code: "abc", OriginalURL: "https://test.com"}, time.Hour); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := repo.SetDisabled(ctx, "abc", true); err != nil {
+		t.Fatalf("SetDisabled() error = %v", err)
+	}
+	got, err := repo.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !got.Disabled || got.OriginalURL != "https://test.com" {
+		t.Errorf("expected a disabled link keeping its url, got %+v", got)
+	}
+	if ttl := s.TTL(linkPrefix + "abc"); ttl != time.Hour {
+		t.Errorf("expected the ttl to be kept, got %v", ttl)
+	}
+
+	if err := repo.SetDisabled(ctx, "abc", false); err != nil {
+		t.Fatalf("SetDisabled() error = %v", err)
+	}
+	if val, _ := s.Get(linkPrefix + "abc"); val != "https://test.com" {
+		t.Errorf("expected the plain url once enabled, got %q", val)
+	}
+
+	if err := repo.SetDisabled(ctx, "missing", true); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+	if s.Exists(linkPrefix + "missing") {
+		t.Error("expected no entry to be created for a missing link")
+	}
+}
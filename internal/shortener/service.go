@@ -5,23 +5,80 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
-	"net/url"
 	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/metrics"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+	"github.com/fernandesenzo/shortener/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// clientErrors are caused by the caller's input and do not mark spans as failed.
+var clientErrors = []error{
+	domain.ErrLinkNotFound,
+	domain.ErrLinkExpired,
+	domain.ErrLinkDisabled,
+	domain.ErrInvalidURL,
+	domain.ErrURLTooLong,
+	domain.ErrUnsupportedURLScheme,
+	domain.ErrSelfReferencingURL,
+	domain.ErrShortenerURL,
+	domain.ErrPrivateURL,
+	domain.ErrBlockedDomain,
+	domain.ErrInvalidExpiration,
+	domain.ErrInvalidAlias,
+	domain.ErrAliasReserved,
+	domain.ErrAliasAlreadyTaken,
+	domain.ErrAliasRequiresAuth,
+	domain.ErrUserExceededLinkLimit,
+	domain.ErrUserNotAuthenticated,
+	domain.ErrUserCannotDeleteLink,
+	domain.ErrUserCannotUpdateLink,
+	domain.ErrInvalidCursor,
+	domain.ErrInvalidPagination,
+}
+
+type ShortenOptions struct {
+	Alias     string
+	ExpiresAt *time.Time
+}
+
+type ListQuery struct {
+	Limit       int
+	Cursor      string
+	Ascending   bool
+	URLContains string
+}
+
+type LinkPage struct {
+	Links      []*domain.PermanentLink
+	NextCursor string
+}
+
 type Service struct {
-	repo LinkRepository
+	repo         LinkRepository
+	temporaryTTL time.Duration
+	urls         *URLValidator
 }
 
-func NewService(repo LinkRepository) *Service {
+// NewService returns a service creating anonymous links that live for temporaryTTL.
+// Destinations are checked by urls; a nil validator applies the default rules only.
+func NewService(repo LinkRepository, temporaryTTL time.Duration, urls *URLValidator) *Service {
+	if urls == nil {
+		urls = NewURLValidator(URLValidatorConfig{})
+	}
 	return &Service{
-		repo: repo,
+		repo:         repo,
+		temporaryTTL: temporaryTTL,
+		urls:         urls,
 	}
 }
-func (s *Service) Delete(ctx context.Context, code string) error {
+func (s *Service) Delete(ctx context.Context, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.Delete", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
 	// it would be nice to validate the code size here
 	uid, ok := identity.GetUserID(ctx)
 	if !ok {
@@ -36,12 +93,65 @@ func (s *Service) Delete(ctx context.Context, code string) error {
 	}
 	return nil
 }
-func (s *Service) Shorten(ctx context.Context, originalURL string, userID string) (domain.Link, error) {
-	if err := validateURL(originalURL); err != nil {
+
+// DeleteAny deletes a link regardless of its owner. It is meant for administrators.
+func (s *Service) DeleteAny(ctx context.Context, code string) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.DeleteAny", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
+	if err := s.repo.DeleteAny(ctx, code); err != nil {
+		if errors.Is(err, ErrNoLinkDeleted) {
+			return domain.ErrLinkNotFound
+		}
+		slog.ErrorContext(ctx, "error deleting code", "code", code, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "link deleted by administrator", "event", "link.admin_deleted", "code", code)
+	return nil
+}
+
+func (s *Service) Update(ctx context.Context, code string, originalURL string) (_ *domain.PermanentLink, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Update", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	if err := s.urls.Validate(originalURL); err != nil {
+		return nil, err
+	}
+	link, err := s.repo.Update(ctx, code, uid, originalURL)
+	if err != nil {
+		if errors.Is(err, ErrNoLinkUpdated) {
+			return nil, domain.ErrUserCannotUpdateLink
+		}
+		slog.ErrorContext(ctx, "error updating link", "userID", uid, "code", code, "error", err)
+		return nil, err
+	}
+	return link, nil
+}
+
+func (s *Service) Shorten(ctx context.Context, originalURL string, userID string, opts ShortenOptions) (_ domain.Link, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Shorten", attribute.Bool("link.permanent", userID != ""))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
+	if err := s.urls.Validate(originalURL); err != nil {
 		return nil, err
 	}
+	if opts.Alias != "" {
+		if userID == "" {
+			return nil, domain.ErrAliasRequiresAuth
+		}
+		if err := validateAlias(opts.Alias); err != nil {
+			return nil, err
+		}
+	}
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return nil, domain.ErrInvalidExpiration
+	}
 
-	link, err := s.saveLink(ctx, userID, originalURL)
+	link, err := s.saveLink(ctx, userID, originalURL, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -49,66 +159,154 @@ func (s *Service) Shorten(ctx context.Context, originalURL string, userID string
 	return link, nil
 }
 
-func (s *Service) Get(ctx context.Context, code string) (domain.Link, error) {
+func (s *Service) Get(ctx context.Context, code string) (_ domain.Link, err error) {
+	ctx, span := tracing.Start(ctx, "Service.Get", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
 	link, err := s.repo.Get(ctx, code)
 
 	if err != nil {
 		if errors.Is(err, ErrRecordNotFound) {
 			return nil, domain.ErrLinkNotFound
 		}
+		if errors.Is(err, ErrRecordExpired) {
+			return nil, domain.ErrLinkExpired
+		}
 		slog.ErrorContext(ctx, "failed to get link", "error", err, "code", code)
 		return nil, fmt.Errorf("unexpected database error: %w", err)
 	}
+	if link.IsDisabled() {
+		return nil, domain.ErrLinkDisabled
+	}
 
 	return link, nil
 }
 
-func (s *Service) saveLink(ctx context.Context, userID string, originalURL string) (domain.Link, error) {
+// SetDisabled takes a link down without deleting it, or restores it. Disabled
+// links stop redirecting but keep their code, so it cannot be reused. It is
+// meant for administrators.
+func (s *Service) SetDisabled(ctx context.Context, code string, disabled bool) (err error) {
+	ctx, span := tracing.Start(ctx, "Service.SetDisabled", attribute.String("link.code", code))
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
+	if err := s.repo.SetDisabled(ctx, code, disabled); err != nil {
+		if errors.Is(err, ErrNoLinkUpdated) {
+			return domain.ErrLinkNotFound
+		}
+		slog.ErrorContext(ctx, "error disabling link", "code", code, "error", err)
+		return err
+	}
+	if disabled {
+		slog.InfoContext(ctx, "link disabled by administrator", "event", "link.disabled", "code", code)
+	} else {
+		slog.InfoContext(ctx, "link enabled by administrator", "event", "link.enabled", "code", code)
+	}
+	return nil
+}
+
+func (s *Service) List(ctx context.Context, query ListQuery) (_ *LinkPage, err error) {
+	ctx, span := tracing.Start(ctx, "Service.List")
+	defer func() { tracing.End(span, err, clientErrors...) }()
+
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+
+	limit, after, err := pagination.Prepare(query.Limit, query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	params := ListParams{
+		Limit:       limit + 1,
+		After:       after,
+		Ascending:   query.Ascending,
+		URLContains: query.URLContains,
+	}
+
+	links, err := s.repo.ListByUser(ctx, uid, params)
+	if err != nil {
+		slog.ErrorContext(ctx, "error listing links", "userID", uid, "error", err)
+		return nil, err
+	}
+
+	page := &LinkPage{}
+	page.Links, page.NextCursor = pagination.Page(links, limit, func(l *domain.PermanentLink) (time.Time, string) {
+		return l.CreatedAt, l.ID
+	})
+	return page, nil
+}
+
+func (s *Service) saveLink(ctx context.Context, userID string, originalURL string, opts ShortenOptions) (domain.Link, error) {
+	if opts.Alias != "" {
+		link, err := s.saveWithCode(ctx, userID, originalURL, opts.Alias, opts.ExpiresAt)
+		if errors.Is(err, ErrRecordAlreadyExists) {
+			return nil, domain.ErrAliasAlreadyTaken
+		}
+		return link, err
+	}
+
 	for i := 0; i < 10; i++ {
 		code, err := GenerateCode(6)
 		if err != nil {
 			return nil, fmt.Errorf("internal error generating code: %w", err)
 		}
-		if userID == "" {
-			link := &domain.TemporaryLink{
-				OriginalURL: originalURL,
-				Code:        code,
-			}
-			if err := s.repo.TempSave(ctx, link, 24*time.Hour); err != nil {
-				if errors.Is(err, ErrRecordAlreadyExists) {
-					continue
-				}
-				return nil, fmt.Errorf("failed to save link: %w", err)
+		link, err := s.saveWithCode(ctx, userID, originalURL, code, opts.ExpiresAt)
+		if err != nil {
+			if errors.Is(err, ErrRecordAlreadyExists) {
+				metrics.IncCodeCollision()
+				continue
 			}
-			return link, nil
+			return nil, err
 		}
-		link := &domain.PermanentLink{
-			Code:        code,
+		return link, nil
+	}
+	return nil, domain.ErrLinkCreationFailed
+}
+
+func (s *Service) saveWithCode(ctx context.Context, userID string, originalURL string, code string, expiresAt *time.Time) (domain.Link, error) {
+	if userID == "" {
+		link := &domain.TemporaryLink{
 			OriginalURL: originalURL,
-			UserID:      userID,
-			CreatedAt:   time.Now(),
+			Code:        code,
+		}
+		ttl := s.temporaryTTL
+		if expiresAt != nil {
+			ttl = min(ttl, time.Until(*expiresAt))
+		}
+		// redis rejects expirations under a millisecond, and the deadline may have
+		// passed since it was validated
+		if ttl < time.Millisecond {
+			return nil, domain.ErrInvalidExpiration
 		}
-		if err := s.repo.PermSave(ctx, link); err != nil {
+		if err := s.repo.TempSave(ctx, link, ttl); err != nil {
 			if errors.Is(err, ErrRecordAlreadyExists) {
-				continue
-			}
-			if errors.Is(err, ErrLimitExceeded) {
-				return nil, domain.ErrUserExceededLinkLimit
+				return nil, err
 			}
 			return nil, fmt.Errorf("failed to save link: %w", err)
 		}
 		return link, nil
 	}
-	return nil, domain.ErrLinkCreationFailed
-}
-
-func validateURL(originalURL string) error {
-	if len(originalURL) > 100 {
-		return domain.ErrURLTooLong
+	link := &domain.PermanentLink{
+		Code:        code,
+		OriginalURL: originalURL,
+		UserID:      userID,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   expiresAt,
 	}
-	_, err := url.ParseRequestURI(originalURL)
-	if err != nil {
-		return domain.ErrInvalidURL
+	if err := s.repo.PermSave(ctx, link); err != nil {
+		if errors.Is(err, ErrRecordAlreadyExists) {
+			return nil, err
+		}
+		if errors.Is(err, ErrLimitExceeded) {
+			return nil, domain.ErrUserExceededLinkLimit
+		}
+		// the account no longer exists while its access token is still valid
+		if errors.Is(err, ErrOwnerNotFound) {
+			return nil, domain.ErrUserNotAuthenticated
+		}
+		return nil, fmt.Errorf("failed to save link: %w", err)
 	}
-	return nil
+	return link, nil
 }
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/identity"
@@ -13,7 +14,7 @@ import (
 
 func TestServiceShorten_Validation(t *testing.T) {
 	repo := &MockRepository{}
-	service := shortener.NewService(repo)
+	service := shortener.NewService(repo, 24*time.Hour, nil)
 
 	tests := []struct {
 		name        string
@@ -34,7 +35,7 @@ func TestServiceShorten_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := service.Shorten(context.Background(), tt.originalURL, "")
+			_, err := service.Shorten(context.Background(), tt.originalURL, "", shortener.ShortenOptions{})
 			if !errors.Is(err, tt.expectedErr) {
 				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
 			}
@@ -66,9 +67,9 @@ func TestServiceShorten_SaveStrategy(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := &MockRepository{}
-			service := shortener.NewService(repo)
+			service := shortener.NewService(repo, 24*time.Hour, nil)
 
-			_, err := service.Shorten(context.Background(), "https://google.com", tt.userID)
+			_, err := service.Shorten(context.Background(), "https://google.com", tt.userID, shortener.ShortenOptions{})
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -84,6 +85,16 @@ func TestServiceShorten_SaveStrategy(t *testing.T) {
 	}
 }
 
+func TestServiceShorten_DeletedOwner(t *testing.T) {
+	repo := &MockRepository{ownerMissing: true}
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+
+	_, err := service.Shorten(context.Background(), "https://google.com", "123", shortener.ShortenOptions{})
+	if !errors.Is(err, domain.ErrUserNotAuthenticated) {
+		t.Fatalf("expected %v, got %v", domain.ErrUserNotAuthenticated, err)
+	}
+}
+
 func TestServiceShorten_Collisions(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -107,9 +118,137 @@ func TestServiceShorten_Collisions(t *testing.T) {
 			repo := &MockRepository{}
 			repo.SetCollisionCounter(tt.mockCollisions)
 
-			service := shortener.NewService(repo)
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+
+			_, err := service.Shorten(context.Background(), "https://google.com", "", shortener.ShortenOptions{})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestServiceShorten_Alias(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		alias       string
+		existing    *domain.PermanentLink
+		expectedErr error
+	}{
+		{
+			name:        "Success",
+			userID:      "123",
+			alias:       "q4-launch",
+			expectedErr: nil,
+		},
+		{
+			name:        "Unlogged user cannot use alias",
+			userID:      "",
+			alias:       "q4-launch",
+			expectedErr: domain.ErrAliasRequiresAuth,
+		},
+		{
+			name:        "Too short",
+			userID:      "123",
+			alias:       "ab",
+			expectedErr: domain.ErrInvalidAlias,
+		},
+		{
+			name:        "Too long",
+			userID:      "123",
+			alias:       strings.Repeat("a", 33),
+			expectedErr: domain.ErrInvalidAlias,
+		},
+		{
+			name:        "Invalid characters",
+			userID:      "123",
+			alias:       "q4/launch",
+			expectedErr: domain.ErrInvalidAlias,
+		},
+		{
+			name:        "Reserved word",
+			userID:      "123",
+			alias:       "API",
+			expectedErr: domain.ErrAliasReserved,
+		},
+		{
+			name:        "Already taken",
+			userID:      "123",
+			alias:       "q4-launch",
+			existing:    &domain.PermanentLink{Code: "q4-launch", OriginalURL: "https://other.com", UserID: "456"},
+			expectedErr: domain.ErrAliasAlreadyTaken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			if tt.existing != nil {
+				_ = repo.save(context.Background(), tt.existing)
+			}
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+
+			link, err := service.Shorten(context.Background(), "https://google.com", tt.userID, shortener.ShortenOptions{Alias: tt.alias})
+			if !errors.Is(err, tt.expectedErr) {
+				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
+			}
+			if tt.expectedErr == nil && link.GetCode() != tt.alias {
+				t.Errorf("expected code %q, got %q", tt.alias, link.GetCode())
+			}
+		})
+	}
+}
+
+func TestServiceShorten_Expiration(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+	imminent := time.Now().Add(500 * time.Microsecond)
+
+	tests := []struct {
+		name        string
+		userID      string
+		expiresAt   *time.Time
+		expectedErr error
+	}{
+		{
+			name:        "No expiration",
+			userID:      "123",
+			expiresAt:   nil,
+			expectedErr: nil,
+		},
+		{
+			name:        "Future expiration",
+			userID:      "123",
+			expiresAt:   &future,
+			expectedErr: nil,
+		},
+		{
+			name:        "Past expiration",
+			userID:      "123",
+			expiresAt:   &past,
+			expectedErr: domain.ErrInvalidExpiration,
+		},
+		{
+			name:        "Unlogged user with future expiration",
+			userID:      "",
+			expiresAt:   &future,
+			expectedErr: nil,
+		},
+		{
+			name:        "Unlogged user with expiration under a millisecond away",
+			userID:      "",
+			expiresAt:   &imminent,
+			expectedErr: domain.ErrInvalidExpiration,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			service := shortener.NewService(repo, 24*time.Hour, nil)
 
-			_, err := service.Shorten(context.Background(), "https://google.com", "")
+			_, err := service.Shorten(context.Background(), "https://google.com", tt.userID, shortener.ShortenOptions{ExpiresAt: tt.expiresAt})
 			if !errors.Is(err, tt.expectedErr) {
 				t.Fatalf("expected %v, got %v", tt.expectedErr, err)
 			}
@@ -117,13 +256,25 @@ func TestServiceShorten_Collisions(t *testing.T) {
 	}
 }
 
+func TestServiceGet_Expired(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.PermanentLink{Code: "old123", OriginalURL: "https://google.com", UserID: "123", ExpiresAt: &past})
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+
+	_, err := service.Get(context.Background(), "old123")
+	if !errors.Is(err, domain.ErrLinkExpired) {
+		t.Fatalf("expected %v, got %v", domain.ErrLinkExpired, err)
+	}
+}
+
 func TestServiceShorten_RepositoryError(t *testing.T) {
 	repo := &MockRepository{}
 	repo.SetShouldError(true)
 
-	service := shortener.NewService(repo)
+	service := shortener.NewService(repo, 24*time.Hour, nil)
 
-	_, err := service.Shorten(context.Background(), "https://google.com", "123")
+	_, err := service.Shorten(context.Background(), "https://google.com", "123", shortener.ShortenOptions{})
 
 	if err == nil {
 		t.Fatal("expected error, got nil")
@@ -168,7 +319,7 @@ func TestServiceGet(t *testing.T) {
 				if tt.setupLink != nil {
 					_ = repo.save(context.Background(), tt.setupLink)
 				}
-				service := shortener.NewService(repo)
+				service := shortener.NewService(repo, 24*time.Hour, nil)
 				_, err := service.Get(context.Background(), tt.code)
 
 				if err != nil {
@@ -244,7 +395,7 @@ func TestServiceDelete(t *testing.T) {
 				_ = repo.save(context.Background(), tt.setupLink)
 			}
 
-			service := shortener.NewService(repo)
+			service := shortener.NewService(repo, 24*time.Hour, nil)
 			ctx := context.Background()
 
 			if tt.authUserID != "" {
@@ -258,3 +409,204 @@ func TestServiceDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceDeleteAny(t *testing.T) {
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.PermanentLink{Code: "del123", OriginalURL: "https://google.com", UserID: "user1"})
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+	ctx := identity.WithUserID(context.Background(), "admin")
+
+	if err := service.DeleteAny(ctx, "del123"); err != nil {
+		t.Fatalf("DeleteAny() error = %v", err)
+	}
+	if err := service.DeleteAny(ctx, "del123"); !errors.Is(err, domain.ErrLinkNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrLinkNotFound, err)
+	}
+}
+
+func TestServiceSetDisabled(t *testing.T) {
+	repo := &MockRepository{}
+	_ = repo.save(context.Background(), &domain.PermanentLink{Code: "perm12", OriginalURL: "https://google.com", UserID: "user1"})
+	_ = repo.save(context.Background(), &domain.TemporaryLink{Code: "temp12", OriginalURL: "https://google.com"})
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+	ctx := context.Background()
+
+	for _, code := range []string{"perm12", "temp12"} {
+		if err := service.SetDisabled(ctx, code, true); err != nil {
+			t.Fatalf("SetDisabled(%s) error = %v", code, err)
+		}
+		if _, err := service.Get(ctx, code); !errors.Is(err, domain.ErrLinkDisabled) {
+			t.Errorf("expected %v for %s, got %v", domain.ErrLinkDisabled, code, err)
+		}
+		if err := service.SetDisabled(ctx, code, false); err != nil {
+			t.Fatalf("SetDisabled(%s) error = %v", code, err)
+		}
+		if _, err := service.Get(ctx, code); err != nil {
+			t.Errorf("expected %s to redirect again, got %v", code, err)
+		}
+	}
+
+	if err := service.SetDisabled(ctx, "ghost", true); !errors.Is(err, domain.ErrLinkNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrLinkNotFound, err)
+	}
+}
+
+func TestServiceList(t *testing.T) {
+	repo := &MockRepository{}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, url := range []string{"https://a.com", "https://b.com/docs", "https://c.com", "https://d.com/docs", "https://e.com"} {
+		_ = repo.save(context.Background(), &domain.PermanentLink{
+			ID:          string(rune('a' + i)),
+			Code:        "code" + string(rune('a'+i)),
+			OriginalURL: url,
+			UserID:      "user1",
+			CreatedAt:   base.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	_ = repo.save(context.Background(), &domain.PermanentLink{ID: "z", Code: "other", OriginalURL: "https://z.com", UserID: "user2", CreatedAt: base})
+
+	service := shortener.NewService(repo, 24*time.Hour, nil)
+	ctx := identity.WithUserID(context.Background(), "user1")
+
+	t.Run("Paginates newest first", func(t *testing.T) {
+		var codes []string
+		cursor := ""
+		for pages := 0; ; pages++ {
+			if pages > 5 {
+				t.Fatal("pagination did not terminate")
+			}
+			page, err := service.List(ctx, shortener.ListQuery{Limit: 2, Cursor: cursor})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			for _, link := range page.Links {
+				codes = append(codes, link.Code)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			cursor = page.NextCursor
+		}
+
+		want := "codee,coded,codec,codeb,codea"
+		if got := strings.Join(codes, ","); got != want {
+			t.Errorf("expected %s, got %s", want, got)
+		}
+	})
+
+	t.Run("Ascending with filter", func(t *testing.T) {
+		page, err := service.List(ctx, shortener.ListQuery{Ascending: true, URLContains: "DOCS"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(page.Links) != 2 || page.Links[0].Code != "codeb" || page.Links[1].Code != "coded" {
+			t.Errorf("unexpected links: %+v", page.Links)
+		}
+		if page.NextCursor != "" {
+			t.Errorf("expected no next cursor, got %q", page.NextCursor)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			ctx         context.Context
+			query       shortener.ListQuery
+			expectedErr error
+		}{
+			{
+				name:        "Unauthenticated",
+				ctx:         identity.WithUserID(context.Background(), ""),
+				expectedErr: domain.ErrUserNotAuthenticated,
+			},
+			{
+				name:        "Invalid cursor",
+				ctx:         ctx,
+				query:       shortener.ListQuery{Cursor: "not-a-cursor"},
+				expectedErr: domain.ErrInvalidCursor,
+			},
+			{
+				name:        "Limit too high",
+				ctx:         ctx,
+				query:       shortener.ListQuery{Limit: 1000},
+				expectedErr: domain.ErrInvalidPagination,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.List(tt.ctx, tt.query)
+				if !errors.Is(err, tt.expectedErr) {
+					t.Errorf("expected %v, got %v", tt.expectedErr, err)
+				}
+			})
+		}
+	})
+}
+
+func TestServiceUpdate(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          string
+		url           string
+		authUserID    string
+		expectedError error
+	}{
+		{
+			name:          "Success",
+			code:          "upd123",
+			url:           "https://new.com",
+			authUserID:    "user1",
+			expectedError: nil,
+		},
+		{
+			name:          "Unauthenticated User",
+			code:          "upd123",
+			url:           "https://new.com",
+			authUserID:    "",
+			expectedError: domain.ErrUserNotAuthenticated,
+		},
+		{
+			name:          "Invalid URL",
+			code:          "upd123",
+			url:           "invalid",
+			authUserID:    "user1",
+			expectedError: domain.ErrInvalidURL,
+		},
+		{
+			name:          "Wrong Owner",
+			code:          "upd123",
+			url:           "https://new.com",
+			authUserID:    "hacker_user",
+			expectedError: domain.ErrUserCannotUpdateLink,
+		},
+		{
+			name:          "Link Not Found",
+			code:          "ghost",
+			url:           "https://new.com",
+			authUserID:    "user1",
+			expectedError: domain.ErrUserCannotUpdateLink,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &MockRepository{}
+			_ = repo.save(context.Background(), &domain.PermanentLink{
+				Code:        "upd123",
+				OriginalURL: "https://old.com",
+				UserID:      "user1",
+			})
+			service := shortener.NewService(repo, 24*time.Hour, nil)
+			ctx := identity.WithUserID(context.Background(), tt.authUserID)
+
+			link, err := service.Update(ctx, tt.code, tt.url)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && link.OriginalURL != tt.url {
+				t.Errorf("expected url %q, got %q", tt.url, link.OriginalURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package shortener
+
+import (
+	"net/netip"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+)
+
+const maxURLLength = 100
+
+// knownShorteners are link shorteners whose links would only add a hop, or hide
+// the real destination from the checks below.
+var knownShorteners = []string{
+	"bit.ly", "bl.ink", "buff.ly", "cutt.ly", "goo.gl", "is.gd", "lnkd.in", "ow.ly",
+	"rb.gy", "rebrand.ly", "s.id", "shorturl.at", "t.co", "t.ly", "tiny.cc", "tinyurl.com", "v.gd",
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice but not
+// reported by netip.Addr.IsPrivate.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+type URLValidatorConfig struct {
+	// BaseURL is the origin short links are served from. Links back to it would
+	// redirect in a loop.
+	BaseURL string
+	// ShortenerDomains are rejected on top of the built-in list of known shorteners.
+	ShortenerDomains []string
+	// Blocklist holds domains rejected by the operator. It may be nil.
+	Blocklist *Blocklist
+}
+
+// URLValidator decides which destinations can be shortened. Only http and https
+// URLs are accepted, and never when they point at a local or private address,
+// back at this shortener, at another shortener or at a blocked domain.
+//
+// Hostnames are not resolved, so a public name pointing at a private address
+// passes; the checks are about what users can be redirected to, not about
+// requests this service makes.
+type URLValidator struct {
+	selfHost   string
+	shorteners domainSet
+	blocklist  *Blocklist
+}
+
+func NewURLValidator(cfg URLValidatorConfig) *URLValidator {
+	v := &URLValidator{
+		shorteners: newDomainSet(slices.Concat(knownShorteners, cfg.ShortenerDomains)...),
+		blocklist:  cfg.Blocklist,
+	}
+	if base, err := url.Parse(cfg.BaseURL); err == nil {
+		v.selfHost = normalizeHost(base.Hostname())
+	}
+	return v
+}
+
+func (v *URLValidator) Validate(rawURL string) error {
+	if len(rawURL) > maxURLLength {
+		return domain.ErrURLTooLong
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" {
+		return domain.ErrInvalidURL
+	}
+	if scheme := strings.ToLower(u.Scheme); scheme != "http" && scheme != "https" {
+		return domain.ErrUnsupportedURLScheme
+	}
+	host := normalizeHost(u.Hostname())
+	if host == "" {
+		return domain.ErrInvalidURL
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if isPrivateAddr(addr.Unmap()) {
+			return domain.ErrPrivateURL
+		}
+	} else if isNumericLabel(host[strings.LastIndex(host, ".")+1:]) {
+		// shorthand IPv4 forms such as 2130706433, 0x7f000001 or 127.1, which
+		// browsers resolve but ParseAddr rejects. No top level domain is numeric.
+		return domain.ErrInvalidURL
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return domain.ErrPrivateURL
+	}
+
+	if v.selfHost != "" && host == v.selfHost {
+		return domain.ErrSelfReferencingURL
+	}
+	if v.shorteners.contains(host) {
+		return domain.ErrShortenerURL
+	}
+	if v.blocklist != nil && v.blocklist.Blocks(host) {
+		return domain.ErrBlockedDomain
+	}
+	return nil
+}
+
+func isPrivateAddr(addr netip.Addr) bool {
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() ||
+		sharedAddressSpace.Contains(addr)
+}
+
+func isNumericLabel(s string) bool {
+	digits := "0123456789"
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		s, digits = s[2:], "0123456789abcdefABCDEF"
+	}
+	return s != "" && strings.Trim(s, digits) == ""
+}
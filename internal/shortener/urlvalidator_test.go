@@ -0,0 +1,72 @@
+package shortener_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/shortener"
+)
+
+func TestURLValidator_Validate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(path, []byte("# phishing\nevil.example\n\n  Malware.Example.  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	blocklist, err := shortener.LoadBlocklist(path)
+	if err != nil {
+		t.Fatalf("LoadBlocklist() error = %v", err)
+	}
+	validator := shortener.NewURLValidator(shortener.URLValidatorConfig{
+		BaseURL:          "https://sho.rt",
+		ShortenerDomains: []string{"my-other.link"},
+		Blocklist:        blocklist,
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{name: "https", url: "https://example.com/path?q=1"},
+		{name: "http with port", url: "http://example.com:8080"},
+		{name: "public ip", url: "http://93.184.216.34/"},
+		{name: "uppercase scheme", url: "HTTPS://example.com"},
+		{name: "too long", url: "https://example.com/" + string(make([]byte, 100)), wantErr: domain.ErrURLTooLong},
+		{name: "relative", url: "/path", wantErr: domain.ErrInvalidURL},
+		{name: "no host", url: "https:///path", wantErr: domain.ErrInvalidURL},
+		{name: "javascript", url: "javascript:alert(1)", wantErr: domain.ErrUnsupportedURLScheme},
+		{name: "data", url: "data:text/html,<script>alert(1)</script>", wantErr: domain.ErrUnsupportedURLScheme},
+		{name: "ftp", url: "ftp://example.com/file", wantErr: domain.ErrUnsupportedURLScheme},
+		{name: "localhost", url: "http://localhost:8080/admin", wantErr: domain.ErrPrivateURL},
+		{name: "localhost subdomain", url: "http://api.localhost", wantErr: domain.ErrPrivateURL},
+		{name: "loopback", url: "http://127.0.0.1", wantErr: domain.ErrPrivateURL},
+		{name: "loopback ipv6", url: "http://[::1]:80", wantErr: domain.ErrPrivateURL},
+		{name: "mapped loopback", url: "http://[::ffff:127.0.0.1]", wantErr: domain.ErrPrivateURL},
+		{name: "private", url: "http://192.168.1.1/router", wantErr: domain.ErrPrivateURL},
+		{name: "link local metadata", url: "http://169.254.169.254/latest", wantErr: domain.ErrPrivateURL},
+		{name: "unspecified", url: "http://0.0.0.0", wantErr: domain.ErrPrivateURL},
+		{name: "carrier nat", url: "http://100.64.0.1", wantErr: domain.ErrPrivateURL},
+		{name: "decimal ip", url: "http://2130706433", wantErr: domain.ErrInvalidURL},
+		{name: "hex ip", url: "http://0x7f000001", wantErr: domain.ErrInvalidURL},
+		{name: "short ip", url: "http://127.1", wantErr: domain.ErrInvalidURL},
+		{name: "self", url: "https://sho.rt/abc123", wantErr: domain.ErrSelfReferencingURL},
+		{name: "self with trailing dot", url: "http://SHO.RT./abc123", wantErr: domain.ErrSelfReferencingURL},
+		{name: "known shortener", url: "https://bit.ly/xyz", wantErr: domain.ErrShortenerURL},
+		{name: "known shortener subdomain", url: "https://www.tinyurl.com/xyz", wantErr: domain.ErrShortenerURL},
+		{name: "configured shortener", url: "https://my-other.link/xyz", wantErr: domain.ErrShortenerURL},
+		{name: "blocked", url: "https://evil.example/login", wantErr: domain.ErrBlockedDomain},
+		{name: "blocked subdomain", url: "https://login.malware.example", wantErr: domain.ErrBlockedDomain},
+		{name: "lookalike of blocked", url: "https://notevil.example", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validator.Validate(tt.url); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate(%q) = %v, want %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
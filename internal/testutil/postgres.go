@@ -16,14 +16,17 @@ import (
 func SetupTestDB(t *testing.T) (*sql.DB, func()) {
 	ctx := context.Background()
 
-	migrationPath := filepath.Join("..", "platform", "postgres", "migrations", "0001_init_schema.up.sql")
+	migrationPaths, err := filepath.Glob(filepath.Join("..", "platform", "postgres", "migrations", "*.up.sql"))
+	if err != nil || len(migrationPaths) == 0 {
+		t.Fatalf("failed to locate migrations: %v", err)
+	}
 
 	pgContainer, err := postgres.Run(ctx,
 		"postgres:15-alpine",
 		postgres.WithDatabase("testdb"),
 		postgres.WithUsername("postgres"),
 		postgres.WithPassword("postgres"),
-		postgres.WithInitScripts(migrationPath),
+		postgres.WithInitScripts(migrationPaths...),
 		testcontainers.WithWaitStrategy(
 			wait.ForLog("database system is ready to accept connections").
 				WithOccurrence(2).
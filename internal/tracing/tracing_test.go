@@ -0,0 +1,83 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/fernandesenzo/shortener/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+var errExpected = errors.New("expected")
+
+func TestEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	_, ok := tracing.Start(context.Background(), "ok")
+	tracing.End(ok, nil)
+	_, miss := tracing.Start(context.Background(), "miss")
+	tracing.End(miss, errExpected, errExpected)
+	_, failed := tracing.Start(context.Background(), "failed")
+	tracing.End(failed, errors.New("boom"), errExpected)
+
+	want := map[string]codes.Code{"ok": codes.Unset, "miss": codes.Unset, "failed": codes.Error}
+	spans := recorder.Ended()
+	if len(spans) != len(want) {
+		t.Fatalf("expected %d spans, got %d", len(want), len(spans))
+	}
+	for _, span := range spans {
+		if span.Status().Code != want[span.Name()] {
+			t.Errorf("span %q: expected status %v, got %v", span.Name(), want[span.Name()], span.Status().Code)
+		}
+	}
+}
+
+func TestLogHandler(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+
+	var buf bytes.Buffer
+	logger := slog.New(tracing.NewLogHandler(slog.NewJSONHandler(&buf, nil))).With("component", "test")
+
+	ctx, span := tracing.Start(context.Background(), "op")
+	logger.InfoContext(ctx, "inside span")
+	span.End()
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid log output: %v", err)
+	}
+	if record["trace_id"] != span.SpanContext().TraceID().String() {
+		t.Errorf("expected trace id %s, got %v", span.SpanContext().TraceID(), record["trace_id"])
+	}
+
+	buf.Reset()
+	logger.Info("outside span")
+	if bytes.Contains(buf.Bytes(), []byte("trace_id")) {
+		t.Error("expected no trace id outside of a span")
+	}
+}
+
+func TestSetup(t *testing.T) {
+	if _, err := tracing.Setup(context.Background(), tracing.Config{Exporter: "jaeger"}); !errors.Is(err, tracing.ErrUnknownExporter) {
+		t.Errorf("expected %v, got %v", tracing.ErrUnknownExporter, err)
+	}
+
+	path := t.TempDir() + "/traces.json"
+	shutdown, err := tracing.Setup(context.Background(), tracing.Config{ServiceName: "test", Exporter: tracing.ExporterFile, FilePath: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, span := tracing.Start(context.Background(), "op")
+	span.End()
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error on shutdown: %v", err)
+	}
+}
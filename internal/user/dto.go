@@ -1,5 +1,7 @@
 package user
 
+import "time"
+
 type createRequest struct {
 	Nickname string `json:"nickname"`
 	Password string `json:"password"`
@@ -10,3 +12,48 @@ type createResponse struct {
 	Nickname  string `json:"nickname"`
 	CreatedAt string `json:"createdAt"`
 }
+
+type quotaResponse struct {
+	Limit     int `json:"limit"`
+	Used      int `json:"used"`
+	Remaining int `json:"remaining"`
+}
+
+type setQuotaRequest struct {
+	Quota *int `json:"quota"`
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type deleteRequest struct {
+	Password string `json:"password"`
+}
+
+type profileResponse struct {
+	ID        string        `json:"id"`
+	Nickname  string        `json:"nickname"`
+	CreatedAt time.Time     `json:"createdAt"`
+	LinkCount int           `json:"linkCount"`
+	Quota     quotaResponse `json:"quota"`
+}
+
+type updateProfileRequest struct {
+	Nickname string `json:"nickname"`
+}
+
+type adminUserResponse struct {
+	ID         string        `json:"id"`
+	Nickname   string        `json:"nickname"`
+	Role       string        `json:"role"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	DisabledAt *time.Time    `json:"disabledAt,omitempty"`
+	Quota      quotaResponse `json:"quota"`
+}
+
+type listUsersResponse struct {
+	Users      []adminUserResponse `json:"users"`
+	NextCursor string              `json:"nextCursor,omitempty"`
+}
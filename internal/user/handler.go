@@ -2,12 +2,12 @@ package user
 
 import (
 	"encoding/json"
-	"errors"
 	"log/slog"
 	"net/http"
 	"strings"
 
-	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
+	"github.com/fernandesenzo/shortener/internal/problem"
 )
 
 type Handler struct {
@@ -20,19 +20,19 @@ func NewHandler(srv *Service) *Handler {
 
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
-		h.sendError(w, r, "unsupported content type", http.StatusUnsupportedMediaType)
+		problem.UnsupportedMediaType(w, r)
 		return
 	}
 
 	var req createRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.sendError(w, r, "invalid request body", http.StatusBadRequest)
+		problem.InvalidBody(w, r)
 		return
 	}
 
 	user, err := h.srv.Create(r.Context(), req.Nickname, req.Password)
 	if err != nil {
-		h.handleError(w, r, err)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -43,14 +43,183 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func (h *Handler) handleError(w http.ResponseWriter, r *http.Request, err error) {
-	switch {
-	case errors.Is(err, domain.ErrNicknameAlreadyUsed):
-		h.sendError(w, r, "nickname already in use", http.StatusConflict)
-	case errors.Is(err, domain.ErrPasswordTooShort), errors.Is(err, domain.ErrPasswordTooLong):
-		h.sendError(w, r, "invalid password length", http.StatusUnprocessableEntity)
-	default:
-		h.sendError(w, r, "internal server error", http.StatusInternalServerError)
+func (h *Handler) Quota(w http.ResponseWriter, r *http.Request) {
+	quota, err := h.srv.Quota(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, r, http.StatusOK, quotaResponse{
+		Limit:     quota.Limit,
+		Used:      quota.Used,
+		Remaining: quota.Remaining(),
+	})
+}
+
+func (h *Handler) SetQuota(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req setQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	if err := h.srv.SetQuota(r.Context(), r.PathValue("id"), req.Quota); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	query := ListQuery{Cursor: q.Get("cursor")}
+	limit, err := pagination.ParseLimit(q.Get("limit"))
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	query.Limit = limit
+
+	page, err := h.srv.List(r.Context(), query)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	resp := listUsersResponse{
+		Users:      make([]adminUserResponse, 0, len(page.Users)),
+		NextCursor: page.NextCursor,
+	}
+	for _, profile := range page.Users {
+		resp.Users = append(resp.Users, adminUserResponse{
+			ID:         profile.User.ID,
+			Nickname:   profile.User.Nickname,
+			Role:       profile.User.Role,
+			CreatedAt:  profile.User.CreatedAt,
+			DisabledAt: profile.User.DisabledAt,
+			Quota: quotaResponse{
+				Limit:     profile.Quota.Limit,
+				Used:      profile.Quota.Used,
+				Remaining: profile.Quota.Remaining(),
+			},
+		})
+	}
+	h.sendJSON(w, r, http.StatusOK, resp)
+}
+
+func (h *Handler) Disable(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, true)
+}
+
+func (h *Handler) Enable(w http.ResponseWriter, r *http.Request) {
+	h.setDisabled(w, r, false)
+}
+
+func (h *Handler) setDisabled(w http.ResponseWriter, r *http.Request, disabled bool) {
+	if err := h.srv.SetDisabled(r.Context(), r.PathValue("id"), disabled); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.srv.Me(r.Context())
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, r, http.StatusOK, newProfileResponse(profile))
+}
+
+func (h *Handler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req updateProfileRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	profile, err := h.srv.UpdateNickname(r.Context(), req.Nickname)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	h.sendJSON(w, r, http.StatusOK, newProfileResponse(profile))
+}
+
+func (h *Handler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req changePasswordRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	if err := h.srv.ChangePassword(r.Context(), req.CurrentPassword, req.NewPassword); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Content-Type"), "application/json") {
+		problem.UnsupportedMediaType(w, r)
+		return
+	}
+
+	var req deleteRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		problem.InvalidBody(w, r)
+		return
+	}
+
+	if err := h.srv.Delete(r.Context(), req.Password); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newProfileResponse(profile *Profile) profileResponse {
+	return profileResponse{
+		ID:        profile.User.ID,
+		Nickname:  profile.User.Nickname,
+		CreatedAt: profile.User.CreatedAt,
+		LinkCount: profile.Quota.Used,
+		Quota: quotaResponse{
+			Limit:     profile.Quota.Limit,
+			Used:      profile.Quota.Used,
+			Remaining: profile.Quota.Remaining(),
+		},
 	}
 }
 
@@ -61,7 +230,3 @@ func (h *Handler) sendJSON(w http.ResponseWriter, r *http.Request, status int, d
 		slog.ErrorContext(r.Context(), "failed to encode json response", "error", err)
 	}
 }
-
-func (h *Handler) sendError(w http.ResponseWriter, r *http.Request, msg string, status int) {
-	h.sendJSON(w, r, status, map[string]string{"error": msg})
-}
@@ -2,11 +2,16 @@ package user_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
 	"github.com/fernandesenzo/shortener/internal/user"
 )
 
@@ -25,7 +30,7 @@ func TestHandler_Create(t *testing.T) {
 			reqBody:        `{invalid json]}`,
 			repoMock:       &MockRepository{},
 			expectedStatus: http.StatusBadRequest,
-			expectedError:  "invalid request body",
+			expectedError:  "invalid_request_body",
 		},
 		{
 			name:           "successfully create user",
@@ -39,7 +44,7 @@ func TestHandler_Create(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			srv := user.NewService(tt.repoMock)
+			srv := user.NewService(tt.repoMock, nil, nil)
 			handler := user.NewHandler(srv)
 
 			req := httptest.NewRequest(http.MethodPost, "/users", bytes.NewBufferString(tt.reqBody))
@@ -56,15 +61,15 @@ func TestHandler_Create(t *testing.T) {
 				ID        string `json:"id"`
 				Nickname  string `json:"nickname"`
 				CreatedAt string `json:"createdAt"`
-				Error     string `json:"error"`
+				Code      string `json:"code"`
 			}
 
 			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
 				t.Fatalf("error reading response json %v", err)
 			}
 			if tt.expectedError != "" {
-				if resp.Error != tt.expectedError {
-					t.Errorf("expected error %q, got %q", tt.expectedError, resp.Error)
+				if resp.Code != tt.expectedError {
+					t.Errorf("expected error code %q, got %q", tt.expectedError, resp.Code)
 				}
 			} else {
 				if resp.ID != tt.expectedID {
@@ -80,3 +85,238 @@ func TestHandler_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_Quota(t *testing.T) {
+	mock := &MockRepository{quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10, Used: 4}}}
+	handler := user.NewHandler(user.NewService(mock, nil, nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/me/quota", nil)
+	req = req.WithContext(identity.WithUserID(context.Background(), "uuid-123"))
+	rr := httptest.NewRecorder()
+
+	handler.Quota(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected code %d, got %d", http.StatusOK, rr.Code)
+	}
+
+	var resp struct {
+		Limit     int `json:"limit"`
+		Used      int `json:"used"`
+		Remaining int `json:"remaining"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("error reading response json %v", err)
+	}
+	if resp.Limit != 10 || resp.Used != 4 || resp.Remaining != 6 {
+		t.Errorf("unexpected quota response %+v", resp)
+	}
+}
+
+func TestHandler_SetQuota(t *testing.T) {
+	tests := []struct {
+		name           string
+		userID         string
+		reqBody        string
+		expectedStatus int
+	}{
+		{
+			name:           "success",
+			userID:         "uuid-123",
+			reqBody:        `{"quota": 50}`,
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "negative quota",
+			userID:         "uuid-123",
+			reqBody:        `{"quota": -5}`,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "unknown user",
+			userID:         "ghost",
+			reqBody:        `{"quota": 50}`,
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRepository{quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10}}}
+			handler := user.NewHandler(user.NewService(mock, nil, nil))
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/users/"+tt.userID+"/quota", bytes.NewBufferString(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.SetPathValue("id", tt.userID)
+			rr := httptest.NewRecorder()
+
+			handler.SetQuota(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected code %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_AccountManagement(t *testing.T) {
+	tests := []struct {
+		name           string
+		method         string
+		reqBody        string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "change password", method: http.MethodPut, reqBody: `{"currentPassword":"old_password","newPassword":"new_password"}`, expectedStatus: http.StatusNoContent},
+		{name: "change password with wrong current", method: http.MethodPut, reqBody: `{"currentPassword":"bad_password","newPassword":"new_password"}`, expectedStatus: http.StatusForbidden, expectedCode: "password_mismatch"},
+		{name: "change password with unknown field", method: http.MethodPut, reqBody: `{"password":"old_password"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_request_body"},
+		{name: "delete account", method: http.MethodDelete, reqBody: `{"password":"old_password"}`, expectedStatus: http.StatusNoContent},
+		{name: "delete account with wrong password", method: http.MethodDelete, reqBody: `{"password":"bad_password"}`, expectedStatus: http.StatusForbidden, expectedCode: "password_mismatch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, svc, ctx := newAccountFixture(t)
+			handler := user.NewHandler(svc)
+
+			req := httptest.NewRequest(tt.method, "/api/users/me", bytes.NewBufferString(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			if tt.method == http.MethodPut {
+				handler.ChangePassword(rr, req)
+			} else {
+				handler.Delete(rr, req)
+			}
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected code %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedCode != "" && !bytes.Contains(rr.Body.Bytes(), []byte(`"code":"`+tt.expectedCode+`"`)) {
+				t.Errorf("expected code %q, got %s", tt.expectedCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Me(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	newHandler := func() *user.Handler {
+		mock := &MockRepository{
+			users:  []*domain.User{{ID: "uuid-123", Nickname: "enzo", CreatedAt: created}, {ID: "uuid-456", Nickname: "taken"}},
+			quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10, Used: 4}},
+		}
+		return user.NewHandler(user.NewService(mock, nil, nil))
+	}
+
+	t.Run("get", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/users/me", nil)
+		req = req.WithContext(identity.WithUserID(context.Background(), "uuid-123"))
+		rr := httptest.NewRecorder()
+		newHandler().Me(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected code %d, got %d", http.StatusOK, rr.Code)
+		}
+		want := `{"id":"uuid-123","nickname":"enzo","createdAt":"2026-01-02T03:04:05Z","linkCount":4,"quota":{"limit":10,"used":4,"remaining":6}}`
+		if got := strings.TrimSpace(rr.Body.String()); got != want {
+			t.Errorf("unexpected body\n got: %s\nwant: %s", got, want)
+		}
+	})
+
+	tests := []struct {
+		name           string
+		reqBody        string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "rename", reqBody: `{"nickname":"enzo2"}`, expectedStatus: http.StatusOK},
+		{name: "taken", reqBody: `{"nickname":"taken"}`, expectedStatus: http.StatusConflict, expectedCode: "nickname_taken"},
+		{name: "empty", reqBody: `{"nickname":""}`, expectedStatus: http.StatusUnprocessableEntity, expectedCode: "invalid_nickname"},
+		{name: "unknown field", reqBody: `{"password":"x"}`, expectedStatus: http.StatusBadRequest, expectedCode: "invalid_request_body"},
+	}
+	for _, tt := range tests {
+		t.Run("patch "+tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPatch, "/api/users/me", bytes.NewBufferString(tt.reqBody))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(identity.WithUserID(context.Background(), "uuid-123"))
+			rr := httptest.NewRecorder()
+			newHandler().UpdateMe(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected code %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedCode != "" && !strings.Contains(rr.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("expected code %q, got %s", tt.expectedCode, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Admin(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	newHandler := func() *user.Handler {
+		mock := &MockRepository{
+			users:  []*domain.User{{ID: "uuid-123", Nickname: "enzo", Role: domain.RoleAdmin, CreatedAt: created}},
+			quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10, Used: 4}},
+		}
+		return user.NewHandler(user.NewService(mock, &stubSessions{}, nil))
+	}
+
+	t.Run("list", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newHandler().List(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users", nil))
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("expected code %d, got %d", http.StatusOK, rr.Code)
+		}
+		want := `{"users":[{"id":"uuid-123","nickname":"enzo","role":"admin","createdAt":"2026-01-02T03:04:05Z","quota":{"limit":10,"used":4,"remaining":6}}]}`
+		if got := strings.TrimSpace(rr.Body.String()); got != want {
+			t.Errorf("unexpected body\n got: %s\nwant: %s", got, want)
+		}
+	})
+
+	t.Run("list with invalid limit", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		newHandler().List(rr, httptest.NewRequest(http.MethodGet, "/api/admin/users?limit=many", nil))
+		if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"code":"invalid_pagination"`) {
+			t.Errorf("expected invalid_pagination, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	tests := []struct {
+		name           string
+		userID         string
+		ctxUserID      string
+		disable        bool
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "disable", userID: "uuid-123", ctxUserID: "uuid-admin", disable: true, expectedStatus: http.StatusNoContent},
+		{name: "enable", userID: "uuid-123", ctxUserID: "uuid-admin", expectedStatus: http.StatusNoContent},
+		{name: "disable self", userID: "uuid-123", ctxUserID: "uuid-123", disable: true, expectedStatus: http.StatusUnprocessableEntity, expectedCode: "cannot_disable_self"},
+		{name: "unknown user", userID: "ghost", ctxUserID: "uuid-admin", disable: true, expectedStatus: http.StatusNotFound, expectedCode: "user_not_found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+tt.userID+"/disable", nil)
+			req.SetPathValue("id", tt.userID)
+			req = req.WithContext(identity.WithUserID(req.Context(), tt.ctxUserID))
+			rr := httptest.NewRecorder()
+
+			if tt.disable {
+				newHandler().Disable(rr, req)
+			} else {
+				newHandler().Enable(rr, req)
+			}
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected code %d, got %d: %s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedCode != "" && !strings.Contains(rr.Body.String(), `"code":"`+tt.expectedCode+`"`) {
+				t.Errorf("expected code %q, got %s", tt.expectedCode, rr.Body.String())
+			}
+		})
+	}
+}
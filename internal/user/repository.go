@@ -5,10 +5,28 @@ import (
 	"errors"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
 )
 
 type Repository interface {
 	Save(ctx context.Context, user *domain.User) error
+	GetQuota(ctx context.Context, userID string) (*domain.Quota, error)
+	SetQuota(ctx context.Context, userID string, quota *int) error
+	GetByID(ctx context.Context, userID string) (*domain.User, error)
+	// Update saves the nickname of the user.
+	Update(ctx context.Context, user *domain.User) error
+	UpdatePassword(ctx context.Context, userID string, passwordHash string) error
+	// Delete removes the user, along with their links, and returns the codes of those links.
+	Delete(ctx context.Context, userID string) ([]string, error)
+	// List returns every user with their link usage, oldest first.
+	List(ctx context.Context, params ListParams) ([]*Profile, error)
+	// SetDisabled disables or re-enables the account of the user.
+	SetDisabled(ctx context.Context, userID string, disabled bool) error
+}
+
+type ListParams struct {
+	Limit int
+	After *pagination.Cursor
 }
 
 var ErrRecordNotFound = errors.New("record not found")
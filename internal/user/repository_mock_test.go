@@ -3,6 +3,7 @@ package user_test
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
 	"github.com/fernandesenzo/shortener/internal/user"
@@ -12,6 +13,8 @@ var ErrMockedError = errors.New("forced db error")
 
 type MockRepository struct {
 	users       []*domain.User
+	quotas      map[string]*domain.Quota
+	links       map[string][]string
 	shouldError bool
 }
 
@@ -33,3 +36,148 @@ func (m *MockRepository) Save(ctx context.Context, usr *domain.User) error {
 	m.users = append(m.users, usr)
 	return nil
 }
+
+func (m *MockRepository) GetQuota(_ context.Context, userID string) (*domain.Quota, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	quota, ok := m.quotas[userID]
+	if !ok {
+		return nil, user.ErrRecordNotFound
+	}
+	return quota, nil
+}
+
+func (m *MockRepository) SetQuota(_ context.Context, userID string, quota *int) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	q, ok := m.quotas[userID]
+	if !ok {
+		return user.ErrRecordNotFound
+	}
+	q.Limit = 10
+	if quota != nil {
+		q.Limit = *quota
+	}
+	return nil
+}
+
+func (m *MockRepository) GetByID(_ context.Context, userID string) (*domain.User, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	for _, u := range m.users {
+		if u.ID == userID {
+			return u, nil
+		}
+	}
+	return nil, user.ErrRecordNotFound
+}
+
+func (m *MockRepository) Update(_ context.Context, usr *domain.User) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	var target *domain.User
+	for _, u := range m.users {
+		if u.ID != usr.ID && u.Nickname == usr.Nickname {
+			return user.ErrRecordAlreadyExists
+		}
+		if u.ID == usr.ID {
+			target = u
+		}
+	}
+	if target == nil {
+		return user.ErrRecordNotFound
+	}
+	target.Nickname = usr.Nickname
+	usr.CreatedAt = target.CreatedAt
+	return nil
+}
+
+func (m *MockRepository) UpdatePassword(_ context.Context, userID string, passwordHash string) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	for _, u := range m.users {
+		if u.ID == userID {
+			u.PasswordHash = passwordHash
+			return nil
+		}
+	}
+	return user.ErrRecordNotFound
+}
+
+func (m *MockRepository) Delete(_ context.Context, userID string) ([]string, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	for i, u := range m.users {
+		if u.ID == userID {
+			m.users = append(m.users[:i], m.users[i+1:]...)
+			codes := m.links[userID]
+			delete(m.links, userID)
+			return codes, nil
+		}
+	}
+	return nil, user.ErrRecordNotFound
+}
+
+// List expects users to be stored oldest first.
+func (m *MockRepository) List(_ context.Context, params user.ListParams) ([]*user.Profile, error) {
+	if m.shouldError {
+		return nil, ErrMockedError
+	}
+	profiles := []*user.Profile{}
+	for _, u := range m.users {
+		if params.After != nil && !u.CreatedAt.After(params.After.CreatedAt) &&
+			!(u.CreatedAt.Equal(params.After.CreatedAt) && u.ID > params.After.ID) {
+			continue
+		}
+		quota := m.quotas[u.ID]
+		if quota == nil {
+			quota = &domain.Quota{Limit: 10}
+		}
+		profiles = append(profiles, &user.Profile{User: u, Quota: quota})
+		if len(profiles) == params.Limit {
+			break
+		}
+	}
+	return profiles, nil
+}
+
+func (m *MockRepository) SetDisabled(_ context.Context, userID string, disabled bool) error {
+	if m.shouldError {
+		return ErrMockedError
+	}
+	for _, u := range m.users {
+		if u.ID == userID {
+			u.DisabledAt = nil
+			if disabled {
+				now := time.Now()
+				u.DisabledAt = &now
+			}
+			return nil
+		}
+	}
+	return user.ErrRecordNotFound
+}
+
+type stubSessions struct {
+	revoked []string
+}
+
+func (s *stubSessions) RevokeSessions(_ context.Context, userID string) error {
+	s.revoked = append(s.revoked, userID)
+	return nil
+}
+
+type stubLinkCache struct {
+	purged []string
+}
+
+func (c *stubLinkCache) Purge(_ context.Context, codes ...string) error {
+	c.purged = append(c.purged, codes...)
+	return nil
+}
@@ -11,12 +11,15 @@ import (
 )
 
 type PostgresRepository struct {
-	db *sql.DB
+	db           *sql.DB
+	defaultQuota int
 }
 
-func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+func NewPostgresRepository(db *sql.DB, defaultQuota int) *PostgresRepository {
 	return &PostgresRepository{
-		db}
+		db:           db,
+		defaultQuota: defaultQuota,
+	}
 }
 
 func (r *PostgresRepository) Save(ctx context.Context, usr *domain.User) error {
@@ -36,3 +39,195 @@ func (r *PostgresRepository) Save(ctx context.Context, usr *domain.User) error {
 
 	return nil
 }
+
+func (r *PostgresRepository) GetQuota(ctx context.Context, userID string) (*domain.Quota, error) {
+	query := `
+        SELECT COALESCE(u.link_quota, $2), (SELECT COUNT(*) FROM links WHERE user_id = u.id)
+        FROM users u WHERE u.id = $1`
+
+	var quota domain.Quota
+	err := r.db.QueryRowContext(ctx, query, userID, r.defaultQuota).Scan(&quota.Limit, &quota.Used)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("error getting user quota: %w", err)
+	}
+	return &quota, nil
+}
+
+func (r *PostgresRepository) SetQuota(ctx context.Context, userID string, quota *int) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET link_quota = $2 WHERE id = $1`, userID, quota)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("error setting user quota: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) GetByID(ctx context.Context, userID string) (*domain.User, error) {
+	query := `SELECT id, nickname, password_hash, role, created_at, disabled_at FROM users WHERE id = $1`
+
+	var usr domain.User
+	var disabledAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&usr.ID, &usr.Nickname, &usr.PasswordHash, &usr.Role, &usr.CreatedAt, &disabledAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.Is(err, sql.ErrNoRows) || (errors.As(err, &pgErr) && pgErr.Code == "22P02") {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("error getting user: %w", err)
+	}
+	if disabledAt.Valid {
+		usr.DisabledAt = &disabledAt.Time
+	}
+	return &usr, nil
+}
+
+func (r *PostgresRepository) Update(ctx context.Context, usr *domain.User) error {
+	query := `UPDATE users SET nickname = $2 WHERE id = $1 RETURNING created_at`
+	err := r.db.QueryRowContext(ctx, query, usr.ID, usr.Nickname).Scan(&usr.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrRecordNotFound
+		}
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return ErrRecordAlreadyExists
+			case "22P02":
+				return ErrRecordNotFound
+			}
+			return fmt.Errorf("postgres error code %s: %w", pgErr.Code, err)
+		}
+		return fmt.Errorf("error updating user: %w", err)
+	}
+	return nil
+}
+
+func (r *PostgresRepository) UpdatePassword(ctx context.Context, userID string, passwordHash string) error {
+	res, err := r.db.ExecContext(ctx, `UPDATE users SET password_hash = $2 WHERE id = $1`, userID, passwordHash)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("error updating user password: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PostgresRepository) Delete(ctx context.Context, userID string) ([]string, error) {
+	// links, their clicks, refresh tokens and api keys go with the user through ON DELETE CASCADE
+	query := `
+        WITH deleted AS (DELETE FROM users WHERE id = $1 RETURNING id)
+        SELECT d.id, l.code FROM deleted d LEFT JOIN links l ON l.user_id = d.id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return nil, ErrRecordNotFound
+		}
+		return nil, fmt.Errorf("error deleting user: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	codes := []string{}
+	for rows.Next() {
+		var id string
+		var code sql.NullString
+		if err := rows.Scan(&id, &code); err != nil {
+			return nil, fmt.Errorf("error scanning deleted link: %w", err)
+		}
+		found = true
+		if code.Valid {
+			codes = append(codes, code.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error deleting user: %w", err)
+	}
+	if !found {
+		return nil, ErrRecordNotFound
+	}
+	return codes, nil
+}
+
+func (r *PostgresRepository) List(ctx context.Context, params ListParams) ([]*Profile, error) {
+	query := `
+        SELECT u.id, u.nickname, u.role, u.created_at, u.disabled_at,
+               COALESCE(u.link_quota, $1), (SELECT COUNT(*) FROM links WHERE user_id = u.id)
+        FROM users u`
+	args := []any{r.defaultQuota}
+	if params.After != nil {
+		args = append(args, params.After.CreatedAt, params.After.ID)
+		query += ` WHERE (u.created_at, u.id) > ($2, $3)`
+	}
+	args = append(args, params.Limit)
+	query += fmt.Sprintf(" ORDER BY u.created_at, u.id LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+	defer rows.Close()
+
+	profiles := make([]*Profile, 0, params.Limit)
+	for rows.Next() {
+		var usr domain.User
+		var quota domain.Quota
+		var disabledAt sql.NullTime
+		if err := rows.Scan(&usr.ID, &usr.Nickname, &usr.Role, &usr.CreatedAt, &disabledAt, &quota.Limit, &quota.Used); err != nil {
+			return nil, fmt.Errorf("error scanning user: %w", err)
+		}
+		if disabledAt.Valid {
+			usr.DisabledAt = &disabledAt.Time
+		}
+		profiles = append(profiles, &Profile{User: &usr, Quota: &quota})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+	return profiles, nil
+}
+
+func (r *PostgresRepository) SetDisabled(ctx context.Context, userID string, disabled bool) error {
+	// disabling twice keeps the original date
+	query := `UPDATE users SET disabled_at = CASE WHEN $2 THEN COALESCE(disabled_at, now()) END WHERE id = $1`
+	res, err := r.db.ExecContext(ctx, query, userID, disabled)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == "22P02" {
+			return ErrRecordNotFound
+		}
+		return fmt.Errorf("error setting user disabled: %w", err)
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
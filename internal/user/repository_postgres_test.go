@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/pagination"
 	"github.com/fernandesenzo/shortener/internal/testutil"
 	"github.com/fernandesenzo/shortener/internal/user"
 	_ "github.com/lib/pq"
@@ -15,7 +16,7 @@ func TestPostgresRepository_Save(t *testing.T) {
 	db, cleanup := testutil.SetupTestDB(t)
 	defer cleanup()
 
-	repo := user.NewPostgresRepository(db)
+	repo := user.NewPostgresRepository(db, 10)
 	ctx := context.Background()
 
 	t.Run("Save User", func(t *testing.T) {
@@ -70,4 +71,136 @@ func TestPostgresRepository_Save(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("Quota", func(t *testing.T) {
+		usr := &domain.User{Nickname: "quota_user", PasswordHash: "hash"}
+		if err := repo.Save(ctx, usr); err != nil {
+			t.Fatalf("error saving user: %v", err)
+		}
+
+		quota, err := repo.GetQuota(ctx, usr.ID)
+		if err != nil {
+			t.Fatalf("GetQuota() error = %v", err)
+		}
+		if quota.Limit != 10 || quota.Used != 0 {
+			t.Errorf("expected default quota 10 with 0 used, got %+v", quota)
+		}
+
+		custom := 25
+		if err := repo.SetQuota(ctx, usr.ID, &custom); err != nil {
+			t.Fatalf("SetQuota() error = %v", err)
+		}
+		quota, err = repo.GetQuota(ctx, usr.ID)
+		if err != nil {
+			t.Fatalf("GetQuota() error = %v", err)
+		}
+		if quota.Limit != custom {
+			t.Errorf("expected quota %d, got %d", custom, quota.Limit)
+		}
+
+		if err := repo.SetQuota(ctx, "not-a-uuid", &custom); !errors.Is(err, user.ErrRecordNotFound) {
+			t.Errorf("expected %v, got %v", user.ErrRecordNotFound, err)
+		}
+	})
+}
+
+func TestPostgresRepository_Account(t *testing.T) {
+	db, cleanup := testutil.SetupTestDB(t)
+	defer cleanup()
+
+	repo := user.NewPostgresRepository(db, 10)
+	ctx := context.Background()
+
+	usr := &domain.User{Nickname: "leaving_user", PasswordHash: "old_hash"}
+	if err := repo.Save(ctx, usr); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, code := range []string{"bye1", "bye2"} {
+		if _, err := db.ExecContext(ctx, `INSERT INTO links (code, original_url, user_id) VALUES ($1, 'https://example.com', $2)`, code, usr.ID); err != nil {
+			t.Fatalf("failed to insert link: %v", err)
+		}
+	}
+
+	other := &domain.User{Nickname: "staying_user", PasswordHash: "hash"}
+	if err := repo.Save(ctx, other); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := repo.Update(ctx, &domain.User{ID: usr.ID, Nickname: "staying_user"}); !errors.Is(err, user.ErrRecordAlreadyExists) {
+		t.Errorf("expected %v renaming to a taken nickname, got %v", user.ErrRecordAlreadyExists, err)
+	}
+	renamed := &domain.User{ID: usr.ID, Nickname: "renamed_user"}
+	if err := repo.Update(ctx, renamed); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if renamed.CreatedAt.IsZero() {
+		t.Error("expected created-at to be loaded on update")
+	}
+
+	if err := repo.UpdatePassword(ctx, usr.ID, "new_hash"); err != nil {
+		t.Fatalf("UpdatePassword() error = %v", err)
+	}
+	got, err := repo.GetByID(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.PasswordHash != "new_hash" || got.Nickname != "renamed_user" {
+		t.Errorf("unexpected user %+v", got)
+	}
+
+	if got.Role != domain.RoleUser || got.Disabled() {
+		t.Errorf("expected an enabled user with the default role, got %+v", got)
+	}
+
+	if err := repo.SetDisabled(ctx, usr.ID, true); err != nil {
+		t.Fatalf("SetDisabled() error = %v", err)
+	}
+	if got, _ := repo.GetByID(ctx, usr.ID); got == nil || !got.Disabled() {
+		t.Error("expected user to be disabled")
+	}
+	if err := repo.SetDisabled(ctx, usr.ID, false); err != nil {
+		t.Fatalf("SetDisabled() error = %v", err)
+	}
+	if err := repo.SetDisabled(ctx, "not-a-uuid", true); !errors.Is(err, user.ErrRecordNotFound) {
+		t.Errorf("expected %v for malformed id, got %v", user.ErrRecordNotFound, err)
+	}
+
+	first, err := repo.List(ctx, user.ListParams{Limit: 1})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(first) != 1 || first[0].User.ID != usr.ID || first[0].Quota.Used != 2 || first[0].Quota.Limit != 10 {
+		t.Fatalf("expected the oldest user with their links first, got %+v", first)
+	}
+	rest, err := repo.List(ctx, user.ListParams{Limit: 10, After: &pagination.Cursor{CreatedAt: first[0].User.CreatedAt, ID: first[0].User.ID}})
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(rest) != 1 || rest[0].User.ID != other.ID {
+		t.Errorf("expected the remaining user after the cursor, got %+v", rest)
+	}
+
+	codes, err := repo.Delete(ctx, usr.ID)
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(codes) != 2 {
+		t.Errorf("expected the deleted links to be returned, got %v", codes)
+	}
+	var remaining int
+	if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM links WHERE code IN ('bye1', 'bye2')`).Scan(&remaining); err != nil {
+		t.Fatal(err)
+	}
+	if remaining != 0 {
+		t.Errorf("expected links to be deleted with the user, %d left", remaining)
+	}
+
+	if _, err := repo.GetByID(ctx, usr.ID); !errors.Is(err, user.ErrRecordNotFound) {
+		t.Errorf("expected %v after delete, got %v", user.ErrRecordNotFound, err)
+	}
+	if _, err := repo.Delete(ctx, usr.ID); !errors.Is(err, user.ErrRecordNotFound) {
+		t.Errorf("expected %v on second delete, got %v", user.ErrRecordNotFound, err)
+	}
+	if _, err := repo.GetByID(ctx, "not-a-uuid"); !errors.Is(err, user.ErrRecordNotFound) {
+		t.Errorf("expected %v for malformed id, got %v", user.ErrRecordNotFound, err)
+	}
 }
@@ -4,18 +4,56 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/pagination"
 	"github.com/fernandesenzo/shortener/internal/password"
 )
 
+// SessionRevoker ends every session of a user.
+type SessionRevoker interface {
+	RevokeSessions(ctx context.Context, userID string) error
+}
+
+// LinkCache drops cached links so deleted ones stop redirecting at once.
+type LinkCache interface {
+	Purge(ctx context.Context, codes ...string) error
+}
+
+// maxNicknameLength matches the size of the nickname column.
+const maxNicknameLength = 256
+
+// Profile is a user along with their link usage.
+type Profile struct {
+	User  *domain.User
+	Quota *domain.Quota
+}
+
+type ListQuery struct {
+	Limit  int
+	Cursor string
+}
+
+type UserPage struct {
+	Users      []*Profile
+	NextCursor string
+}
+
 type Service struct {
-	repo Repository
+	repo     Repository
+	sessions SessionRevoker
+	links    LinkCache
 }
 
-func NewService(repo Repository) *Service {
+func NewService(repo Repository, sessions SessionRevoker, links LinkCache) *Service {
 	return &Service{
-		repo: repo,
+		repo:     repo,
+		sessions: sessions,
+		links:    links,
 	}
 }
 
@@ -48,3 +86,204 @@ func (s *Service) Create(ctx context.Context, nickname string, pass string) (*do
 	}
 	return user, nil
 }
+
+func (s *Service) Quota(ctx context.Context) (*domain.Quota, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	quota, err := s.repo.GetQuota(ctx, uid)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting user quota", "userID", uid, "error", err)
+		return nil, err
+	}
+	return quota, nil
+}
+
+// SetQuota overrides the link quota of a user. A nil quota resets the user to the default.
+func (s *Service) SetQuota(ctx context.Context, userID string, quota *int) error {
+	if quota != nil && *quota < 0 {
+		return domain.ErrInvalidQuota
+	}
+	if err := s.repo.SetQuota(ctx, userID, quota); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when setting user quota", "userID", userID, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "user quota changed", "userID", userID, "quota", quota)
+	return nil
+}
+
+// List pages through every user, oldest first. It is meant for administrators.
+func (s *Service) List(ctx context.Context, query ListQuery) (*UserPage, error) {
+	limit, after, err := pagination.Prepare(query.Limit, query.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.repo.List(ctx, ListParams{Limit: limit + 1, After: after})
+	if err != nil {
+		slog.ErrorContext(ctx, "unknown db error when listing users", "error", err)
+		return nil, err
+	}
+
+	page := &UserPage{}
+	page.Users, page.NextCursor = pagination.Page(profiles, limit, func(p *Profile) (time.Time, string) {
+		return p.User.CreatedAt, p.User.ID
+	})
+	return page, nil
+}
+
+// SetDisabled disables or re-enables the account of a user. Disabling ends every
+// session of the user; administrators cannot disable themselves.
+func (s *Service) SetDisabled(ctx context.Context, userID string, disabled bool) error {
+	if uid, _ := identity.GetUserID(ctx); disabled && uid == userID {
+		return domain.ErrCannotDisableSelf
+	}
+	if err := s.repo.SetDisabled(ctx, userID, disabled); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when disabling user", "userID", userID, "error", err)
+		return err
+	}
+	if !disabled {
+		slog.InfoContext(ctx, "user enabled", "event", "user.enabled", "userID", userID)
+		return nil
+	}
+	slog.InfoContext(ctx, "user disabled", "event", "user.disabled", "userID", userID)
+	return s.sessions.RevokeSessions(ctx, userID)
+}
+
+// Me returns the profile of the authenticated user.
+func (s *Service) Me(ctx context.Context) (*Profile, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	usr, err := s.repo.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting user", "userID", uid, "error", err)
+		return nil, err
+	}
+	return s.profile(ctx, usr)
+}
+
+// UpdateNickname renames the authenticated user.
+func (s *Service) UpdateNickname(ctx context.Context, nickname string) (*Profile, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	if n := utf8.RuneCountInString(nickname); n == 0 || n > maxNicknameLength || strings.TrimSpace(nickname) == "" {
+		return nil, domain.ErrInvalidNickname
+	}
+
+	usr := &domain.User{ID: uid, Nickname: nickname}
+	if err := s.repo.Update(ctx, usr); err != nil {
+		if errors.Is(err, ErrRecordAlreadyExists) {
+			slog.InfoContext(ctx, "attempt to rename an user to an already existing nickname",
+				"nickname", nickname)
+			return nil, domain.ErrNicknameAlreadyUsed
+		}
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when updating user", "userID", uid, "error", err)
+		return nil, err
+	}
+	return s.profile(ctx, usr)
+}
+
+func (s *Service) profile(ctx context.Context, usr *domain.User) (*Profile, error) {
+	quota, err := s.repo.GetQuota(ctx, usr.ID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting user quota", "userID", usr.ID, "error", err)
+		return nil, err
+	}
+	return &Profile{User: usr, Quota: quota}, nil
+}
+
+// ChangePassword replaces the caller's password and signs them out everywhere.
+func (s *Service) ChangePassword(ctx context.Context, current string, newPassword string) error {
+	usr, err := s.currentUser(ctx, current)
+	if err != nil {
+		return err
+	}
+	if err := password.Validate(newPassword); err != nil {
+		return err
+	}
+	hashed, err := password.Hash(newPassword)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to hash password during password change", "userID", usr.ID, "error", err)
+		return err
+	}
+	if err := s.repo.UpdatePassword(ctx, usr.ID, hashed); err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when updating password", "userID", usr.ID, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "user password changed", "event", "user.password_changed", "userID", usr.ID)
+
+	return s.sessions.RevokeSessions(ctx, usr.ID)
+}
+
+// Delete removes the caller's account and everything they own.
+func (s *Service) Delete(ctx context.Context, pass string) error {
+	usr, err := s.currentUser(ctx, pass)
+	if err != nil {
+		return err
+	}
+	codes, err := s.repo.Delete(ctx, usr.ID)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when deleting user", "userID", usr.ID, "error", err)
+		return err
+	}
+	slog.InfoContext(ctx, "user deleted", "event", "user.deleted", "userID", usr.ID, "links", len(codes))
+
+	// the account is gone at this point, so cleanup failures are logged rather than reported
+	if err := s.links.Purge(ctx, codes...); err != nil {
+		slog.ErrorContext(ctx, "failed to purge cached links of deleted user", "userID", usr.ID, "error", err)
+	}
+	if err := s.sessions.RevokeSessions(ctx, usr.ID); err != nil {
+		slog.ErrorContext(ctx, "failed to revoke sessions of deleted user", "userID", usr.ID, "error", err)
+	}
+	return nil
+}
+
+// currentUser loads the authenticated user and checks their password.
+func (s *Service) currentUser(ctx context.Context, pass string) (*domain.User, error) {
+	uid, ok := identity.GetUserID(ctx)
+	if !ok || uid == "" {
+		return nil, domain.ErrUserNotAuthenticated
+	}
+	usr, err := s.repo.GetByID(ctx, uid)
+	if err != nil {
+		if errors.Is(err, ErrRecordNotFound) {
+			return nil, domain.ErrUserNotFound
+		}
+		slog.ErrorContext(ctx, "unknown db error when getting user", "userID", uid, "error", err)
+		return nil, err
+	}
+	if err := password.Compare(usr.PasswordHash, pass); err != nil {
+		slog.WarnContext(ctx, "wrong password confirmation", "event", "user.password_mismatch", "userID", uid)
+		return nil, domain.ErrPasswordMismatch
+	}
+	return usr, nil
+}
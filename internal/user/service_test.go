@@ -3,9 +3,15 @@ package user_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fernandesenzo/shortener/internal/domain"
+	"github.com/fernandesenzo/shortener/internal/identity"
+	"github.com/fernandesenzo/shortener/internal/password"
 	user2 "github.com/fernandesenzo/shortener/internal/user"
 )
 
@@ -21,7 +27,7 @@ func TestService_Create(t *testing.T) {
 		{
 			name:         "Success",
 			nickname:     "enzo",
-			password:     "password123",
+			password:     "correct horse battery",
 			repoError:    false,
 			preExistUser: false,
 			wantErr:      nil,
@@ -29,14 +35,14 @@ func TestService_Create(t *testing.T) {
 		{
 			name:         "Duplicate Nickname",
 			nickname:     "enzo",
-			password:     "password123",
+			password:     "correct horse battery",
 			preExistUser: true,
 			wantErr:      domain.ErrNicknameAlreadyUsed,
 		},
 		{
 			name:      "Unexpected Repository Error",
 			nickname:  "database_fail",
-			password:  "password123",
+			password:  "correct horse battery",
 			repoError: true,
 			wantErr:   ErrMockedError,
 		},
@@ -54,7 +60,7 @@ func TestService_Create(t *testing.T) {
 				mock.users = append(mock.users, &domain.User{Nickname: tt.nickname})
 			}
 
-			svc := user2.NewService(mock)
+			svc := user2.NewService(mock, nil, nil)
 
 			_, err := svc.Create(context.Background(), tt.nickname, tt.password)
 
@@ -75,3 +81,314 @@ func TestService_Create(t *testing.T) {
 		})
 	}
 }
+
+func TestService_Quota(t *testing.T) {
+	tests := []struct {
+		name          string
+		userID        string
+		expectedError error
+		expectedLimit int
+	}{
+		{
+			name:          "Success",
+			userID:        "uuid-123",
+			expectedError: nil,
+			expectedLimit: 10,
+		},
+		{
+			name:          "Unauthenticated",
+			userID:        "",
+			expectedError: domain.ErrUserNotAuthenticated,
+		},
+		{
+			name:          "Unknown user",
+			userID:        "ghost",
+			expectedError: domain.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRepository{quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10, Used: 4}}}
+			svc := user2.NewService(mock, nil, nil)
+
+			quota, err := svc.Quota(identity.WithUserID(context.Background(), tt.userID))
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && quota.Limit != tt.expectedLimit {
+				t.Errorf("expected limit %d, got %d", tt.expectedLimit, quota.Limit)
+			}
+		})
+	}
+}
+
+func TestService_SetQuota(t *testing.T) {
+	negative := -1
+	fifty := 50
+
+	tests := []struct {
+		name          string
+		userID        string
+		quota         *int
+		expectedError error
+		expectedLimit int
+	}{
+		{
+			name:          "Set custom quota",
+			userID:        "uuid-123",
+			quota:         &fifty,
+			expectedLimit: 50,
+		},
+		{
+			name:          "Reset to default",
+			userID:        "uuid-123",
+			quota:         nil,
+			expectedLimit: 10,
+		},
+		{
+			name:          "Negative quota",
+			userID:        "uuid-123",
+			quota:         &negative,
+			expectedError: domain.ErrInvalidQuota,
+		},
+		{
+			name:          "Unknown user",
+			userID:        "ghost",
+			quota:         &fifty,
+			expectedError: domain.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRepository{quotas: map[string]*domain.Quota{"uuid-123": {Limit: 20}}}
+			svc := user2.NewService(mock, nil, nil)
+
+			err := svc.SetQuota(context.Background(), tt.userID, tt.quota)
+			if !errors.Is(err, tt.expectedError) {
+				t.Fatalf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if tt.expectedError == nil && mock.quotas[tt.userID].Limit != tt.expectedLimit {
+				t.Errorf("expected limit %d, got %d", tt.expectedLimit, mock.quotas[tt.userID].Limit)
+			}
+		})
+	}
+}
+
+func newAccountFixture(t *testing.T) (*MockRepository, *stubSessions, *stubLinkCache, *user2.Service, context.Context) {
+	t.Helper()
+	hash, err := password.Hash("old_password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := &MockRepository{
+		users: []*domain.User{{ID: "uuid-123", Nickname: "enzo", PasswordHash: hash}},
+		links: map[string][]string{"uuid-123": {"abc", "def"}},
+	}
+	sessions := &stubSessions{}
+	cache := &stubLinkCache{}
+	ctx := identity.WithUserID(context.Background(), "uuid-123")
+	return mock, sessions, cache, user2.NewService(mock, sessions, cache), ctx
+}
+
+func TestService_ChangePassword(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		newPassword string
+		wantErr     error
+	}{
+		{name: "success", current: "old_password", newPassword: "new_password"},
+		{name: "wrong current password", current: "nope_nope", newPassword: "new_password", wantErr: domain.ErrPasswordMismatch},
+		{name: "new password too short", current: "old_password", newPassword: "abc", wantErr: domain.ErrPasswordTooShort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, sessions, _, svc, ctx := newAccountFixture(t)
+
+			err := svc.ChangePassword(ctx, tt.current, tt.newPassword)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			changed := password.Compare(mock.users[0].PasswordHash, tt.newPassword) == nil
+			if changed != (tt.wantErr == nil) {
+				t.Errorf("expected password changed = %v", tt.wantErr == nil)
+			}
+			if revoked := len(sessions.revoked) == 1; revoked != (tt.wantErr == nil) {
+				t.Errorf("expected sessions revoked = %v, got %v", tt.wantErr == nil, sessions.revoked)
+			}
+		})
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		_, _, _, svc, _ := newAccountFixture(t)
+		if err := svc.ChangePassword(context.Background(), "old_password", "new_password"); !errors.Is(err, domain.ErrUserNotAuthenticated) {
+			t.Errorf("expected %v, got %v", domain.ErrUserNotAuthenticated, err)
+		}
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mock, sessions, cache, svc, ctx := newAccountFixture(t)
+
+		if err := svc.Delete(ctx, "old_password"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if len(mock.users) != 0 {
+			t.Error("expected user to be deleted")
+		}
+		if len(cache.purged) != 2 || cache.purged[0] != "abc" || cache.purged[1] != "def" {
+			t.Errorf("expected the user's links to be purged, got %v", cache.purged)
+		}
+		if len(sessions.revoked) != 1 {
+			t.Errorf("expected sessions to be revoked, got %v", sessions.revoked)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mock, _, cache, svc, ctx := newAccountFixture(t)
+
+		if err := svc.Delete(ctx, "wrong_password"); !errors.Is(err, domain.ErrPasswordMismatch) {
+			t.Fatalf("expected %v, got %v", domain.ErrPasswordMismatch, err)
+		}
+		if len(mock.users) != 1 || len(cache.purged) != 0 {
+			t.Error("expected nothing to be deleted")
+		}
+	})
+}
+
+func TestService_Me(t *testing.T) {
+	mock := &MockRepository{
+		users:  []*domain.User{{ID: "uuid-123", Nickname: "enzo"}},
+		quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10, Used: 3}},
+	}
+	svc := user2.NewService(mock, nil, nil)
+
+	profile, err := svc.Me(identity.WithUserID(context.Background(), "uuid-123"))
+	if err != nil {
+		t.Fatalf("Me() error = %v", err)
+	}
+	if profile.User.Nickname != "enzo" || profile.Quota.Used != 3 {
+		t.Errorf("unexpected profile %+v %+v", profile.User, profile.Quota)
+	}
+
+	if _, err := svc.Me(identity.WithUserID(context.Background(), "ghost")); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Errorf("expected %v, got %v", domain.ErrUserNotFound, err)
+	}
+	if _, err := svc.Me(context.Background()); !errors.Is(err, domain.ErrUserNotAuthenticated) {
+		t.Errorf("expected %v, got %v", domain.ErrUserNotAuthenticated, err)
+	}
+}
+
+func TestService_UpdateNickname(t *testing.T) {
+	tests := []struct {
+		name     string
+		nickname string
+		wantErr  error
+	}{
+		{name: "success", nickname: "enzo_renamed"},
+		{name: "keeps own nickname", nickname: "enzo"},
+		{name: "taken", nickname: "taken", wantErr: domain.ErrNicknameAlreadyUsed},
+		{name: "empty", nickname: "", wantErr: domain.ErrInvalidNickname},
+		{name: "blank", nickname: "   ", wantErr: domain.ErrInvalidNickname},
+		{name: "too long", nickname: strings.Repeat("a", 257), wantErr: domain.ErrInvalidNickname},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &MockRepository{
+				users:  []*domain.User{{ID: "uuid-123", Nickname: "enzo"}, {ID: "uuid-456", Nickname: "taken"}},
+				quotas: map[string]*domain.Quota{"uuid-123": {Limit: 10}},
+			}
+			svc := user2.NewService(mock, nil, nil)
+
+			profile, err := svc.UpdateNickname(identity.WithUserID(context.Background(), "uuid-123"), tt.nickname)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("UpdateNickname() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && profile.User.Nickname != tt.nickname {
+				t.Errorf("expected nickname %q, got %q", tt.nickname, profile.User.Nickname)
+			}
+			if tt.wantErr != nil && mock.users[0].Nickname != "enzo" {
+				t.Errorf("expected nickname to stay unchanged, got %q", mock.users[0].Nickname)
+			}
+		})
+	}
+}
+
+func TestService_List(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mock := &MockRepository{}
+	for i := range 5 {
+		mock.users = append(mock.users, &domain.User{ID: fmt.Sprintf("uuid-%d", i), Nickname: fmt.Sprintf("user%d", i), CreatedAt: base.Add(time.Duration(i) * time.Hour)})
+	}
+	svc := user2.NewService(mock, nil, nil)
+
+	var seen []string
+	cursor := ""
+	for {
+		page, err := svc.List(context.Background(), user2.ListQuery{Limit: 2, Cursor: cursor})
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, p := range page.Users {
+			seen = append(seen, p.User.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if want := []string{"uuid-0", "uuid-1", "uuid-2", "uuid-3", "uuid-4"}; !slices.Equal(seen, want) {
+		t.Errorf("expected %v, got %v", want, seen)
+	}
+
+	for _, query := range []user2.ListQuery{{Limit: -1}, {Limit: 101}, {Cursor: "???"}} {
+		if _, err := svc.List(context.Background(), query); err == nil {
+			t.Errorf("expected an error for %+v", query)
+		}
+	}
+}
+
+func TestService_SetDisabled(t *testing.T) {
+	admin := identity.WithUserID(context.Background(), "uuid-admin")
+
+	t.Run("disable revokes sessions", func(t *testing.T) {
+		mock, sessions, _, svc, _ := newAccountFixture(t)
+
+		if err := svc.SetDisabled(admin, "uuid-123", true); err != nil {
+			t.Fatalf("SetDisabled() error = %v", err)
+		}
+		if !mock.users[0].Disabled() {
+			t.Error("expected user to be disabled")
+		}
+		if !slices.Equal(sessions.revoked, []string{"uuid-123"}) {
+			t.Errorf("expected sessions to be revoked, got %v", sessions.revoked)
+		}
+
+		if err := svc.SetDisabled(admin, "uuid-123", false); err != nil {
+			t.Fatalf("SetDisabled() error = %v", err)
+		}
+		if mock.users[0].Disabled() {
+			t.Error("expected user to be enabled again")
+		}
+	})
+
+	t.Run("cannot disable self", func(t *testing.T) {
+		_, _, _, svc, ctx := newAccountFixture(t)
+		if err := svc.SetDisabled(ctx, "uuid-123", true); !errors.Is(err, domain.ErrCannotDisableSelf) {
+			t.Errorf("expected %v, got %v", domain.ErrCannotDisableSelf, err)
+		}
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, _, _, svc, _ := newAccountFixture(t)
+		if err := svc.SetDisabled(admin, "ghost", true); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Errorf("expected %v, got %v", domain.ErrUserNotFound, err)
+		}
+	})
+}